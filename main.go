@@ -1,25 +1,186 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/secguro/secguro-cli/pkg/api"
+	"github.com/secguro/secguro-cli/pkg/baseline"
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/configfile"
+	"github.com/secguro/secguro-cli/pkg/dependencies"
+	"github.com/secguro/secguro-cli/pkg/detectors"
 	"github.com/secguro/secguro-cli/pkg/fix"
+	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/hooks"
+	"github.com/secguro/secguro-cli/pkg/logging"
 	"github.com/secguro/secguro-cli/pkg/login"
+	"github.com/secguro/secguro-cli/pkg/profile"
+	"github.com/secguro/secguro-cli/pkg/reporting"
 	"github.com/secguro/secguro-cli/pkg/scan"
+	"github.com/secguro/secguro-cli/pkg/types"
 	"github.com/urfave/cli/v2"
 )
 
 func main() { //nolint: funlen, cyclop
 	var flagGitMode bool
+	var flagSince string
+	var flagStaged bool
 	var flagFormat string
 	var flagOutput string
 	var flagTolerance int
+	var flagBaseline string
+	var flagMinSeverity string
+	var flagFailOn string
+	var flagShowSecrets bool
+	var flagIncludeHandled bool
 	var flagDisabledDetectors []string
+	var flagTimeout time.Duration
+	var flagDetectorTimeouts []string
+	var flagCustomRules []string
+
+	var flagUninstall bool
+
+	var flagExpiry time.Duration
+	var flagOpen bool
+
+	var flagProfile string
+	var flagServerUrl string
+	var flagWebappUrl string
+	var flagHttpTimeout time.Duration
+	var flagHttpRetries int
+	var flagCaBundle string
+	var flagVerbose bool
+	var flagDebug bool
+	var flagQuiet bool
+	var flagOffline bool
+	var flagBundle string
+	var flagBundleOutput string
+	var flagDependencyVersions []string
+	var flagDependencyChecksums []string
+	var flagCleanAll bool
+	var flagDetectorBinaries []string
+
+	// Precedence (highest first): flag, environment variable, user config file, URLs stored
+	// on login in the profile, built-in default. Except on login, URLs that are given
+	// explicitly must not point to another server than the one that has issued the device
+	// token of the profile.
+	applyServerUrls := func(cCtx *cli.Context, configFile configfile.ConfigFile, isLogin bool) error {
+		serverUrls, err := profile.ReadServerUrls()
+		if err != nil {
+			return err
+		}
+
+		for _, serverUrl := range []string{serverUrls.ServerUrl, configFile.ServerUrl} {
+			if serverUrl != "" {
+				config.ServerUrl = serverUrl
+			}
+		}
+		for _, webappUrl := range []string{serverUrls.WebappUrl, configFile.WebappUrl} {
+			if webappUrl != "" {
+				config.WebappUrl = webappUrl
+			}
+		}
+
+		if cCtx.IsSet("server-url") {
+			config.ServerUrl = flagServerUrl
+		}
+		if cCtx.IsSet("webapp-url") {
+			config.WebappUrl = flagWebappUrl
+		}
+
+		if isLogin {
+			return nil
+		}
+
+		return login.CheckServerUrl(serverUrls.ServerUrl)
+	}
 
 	loginAction := func(cCtx *cli.Context) error {
-		return login.CommandLogin()
+		configFile, err := configfile.Load(".")
+		if err != nil {
+			return err
+		}
+		err = applyServerUrls(cCtx, configFile, true)
+		if err != nil {
+			return err
+		}
+
+		loginOptions := login.LoginOptions{
+			Expiry:      flagExpiry,
+			OpenBrowser: flagOpen,
+		}
+
+		return login.CommandLogin(cCtx.Context, loginOptions)
+	}
+
+	// Both act on the server of the current profile.
+	logoutAction := func(cCtx *cli.Context) error {
+		err := applyServerUrls(cCtx, configfile.ConfigFile{}, false) //nolint: exhaustruct
+		if err != nil {
+			return err
+		}
+
+		return login.CommandLogout(cCtx.Context)
+	}
+
+	whoamiAction := func(cCtx *cli.Context) error {
+		err := applyServerUrls(cCtx, configfile.ConfigFile{}, false) //nolint: exhaustruct
+		if err != nil {
+			return err
+		}
+
+		return login.CommandWhoami()
+	}
+
+	reportFlushAction := func(cCtx *cli.Context) error {
+		err := applyServerUrls(cCtx, configfile.ConfigFile{}, false) //nolint: exhaustruct
+		if err != nil {
+			return err
+		}
+
+		return reporting.CommandReportFlush(cCtx.Context)
+	}
+
+	profileListAction := func(cCtx *cli.Context) error {
+		return profile.CommandList()
+	}
+
+	configValidateAction := func(cCtx *cli.Context) error {
+		if cCtx.NArg() > 1 {
+			return errors.New("too many arguments")
+		}
+
+		directory := "."
+		if cCtx.NArg() > 0 {
+			directory = cCtx.Args().Get(0)
+		}
+
+		return configfile.CommandValidate(directory)
+	}
+
+	installHooksAction := func(cCtx *cli.Context) error {
+		if cCtx.NArg() > 1 {
+			return errors.New("too many arguments")
+		}
+
+		directory := "."
+		if cCtx.NArg() > 0 {
+			directory = cCtx.Args().Get(0)
+		}
+
+		return hooks.CommandInstallHooks(directory, flagUninstall)
+	}
+
+	preCommitAction := func(cCtx *cli.Context) error {
+		return hooks.CommandPreCommit(cCtx.Context, ".")
 	}
 
 	flagsScanAndFixMode := []cli.Flag{
@@ -28,21 +189,59 @@ func main() { //nolint: funlen, cyclop
 			Usage:       "set to scan git history and print commit information",
 			Destination: &flagGitMode,
 		},
+		&cli.StringFlag{ //nolint: exhaustruct
+			Name:        "since",
+			Value:       "",
+			Usage:       "only scan files and commits that changed since the given git revision",
+			Destination: &flagSince,
+		},
+		&cli.BoolFlag{ //nolint: exhaustruct
+			Name:        "staged",
+			Usage:       "only scan staged changes",
+			Destination: &flagStaged,
+		},
 		&cli.MultiStringFlag{
 			Target: &cli.StringSliceFlag{ //nolint: exhaustruct
-				Name:  "disabled-detectors",
-				Usage: "list of detectors to disable (semgrep,gitleaks,dependencycheck)",
+				Name:    "disabled-detectors",
+				Usage:   "list of detectors to disable (" + strings.Join(detectors.GetNames(), ",") + ")",
+				EnvVars: []string{"SECGURO_DISABLED_DETECTORS"},
 			},
 			Value:       []string{},
 			Destination: &flagDisabledDetectors,
 		},
+		&cli.DurationFlag{ //nolint: exhaustruct
+			Name:        "timeout",
+			Value:       0,
+			Usage:       "maximum duration of the scan (e.g. 30m); detectors still running are considered failed",
+			EnvVars:     []string{"SECGURO_TIMEOUT"},
+			Destination: &flagTimeout,
+		},
+		&cli.MultiStringFlag{
+			Target: &cli.StringSliceFlag{ //nolint: exhaustruct
+				Name:    "detector-timeout",
+				Usage:   "maximum duration of a single detector (e.g. dependencycheck=20m)",
+				EnvVars: []string{"SECGURO_DETECTOR_TIMEOUTS"},
+			},
+			Value:       []string{},
+			Destination: &flagDetectorTimeouts,
+		},
+		&cli.MultiStringFlag{
+			Target: &cli.StringSliceFlag{ //nolint: exhaustruct
+				Name:    "custom-rules",
+				Usage:   "path to a file or directory of additional semgrep rules",
+				EnvVars: []string{"SECGURO_CUSTOM_RULES"},
+			},
+			Value:       []string{},
+			Destination: &flagCustomRules,
+		},
 	}
 
 	flagsOnlyScanMode := []cli.Flag{
 		&cli.StringFlag{ //nolint: exhaustruct
 			Name:        "format",
 			Value:       "text",
-			Usage:       "text or json",
+			Usage:       "text, json or sarif",
+			EnvVars:     []string{"SECGURO_FORMAT"},
 			Destination: &flagFormat,
 		},
 		&cli.StringFlag{ //nolint: exhaustruct
@@ -56,8 +255,97 @@ func main() { //nolint: funlen, cyclop
 			Name:        "tolerance",
 			Value:       0,
 			Usage:       "number of findings to tolerate when choosing exit code",
+			EnvVars:     []string{"SECGURO_TOLERANCE"},
 			Destination: &flagTolerance,
 		},
+		&cli.StringFlag{ //nolint: exhaustruct
+			Name:        "baseline",
+			Value:       "",
+			Usage:       "path to a baseline file; only findings missing from it are considered",
+			Destination: &flagBaseline,
+		},
+		&cli.StringFlag{ //nolint: exhaustruct
+			Name:        "min-severity",
+			Value:       "info",
+			Usage:       "hide findings below this severity (info, low, medium, high or critical)",
+			EnvVars:     []string{"SECGURO_MIN_SEVERITY"},
+			Destination: &flagMinSeverity,
+		},
+		&cli.StringFlag{ //nolint: exhaustruct
+			Name:        "fail-on",
+			Value:       "info",
+			Usage:       "only count findings of at least this severity when choosing exit code",
+			EnvVars:     []string{"SECGURO_FAIL_ON"},
+			Destination: &flagFailOn,
+		},
+		&cli.BoolFlag{ //nolint: exhaustruct
+			Name:        "show-secrets",
+			Usage:       "print and report found secrets in full instead of redacting them",
+			Destination: &flagShowSecrets,
+		},
+	}
+
+	flagsOnlyFixMode := []cli.Flag{
+		&cli.BoolFlag{ //nolint: exhaustruct
+			Name:        "include-handled",
+			Usage:       "also list findings that have already been handled in previous runs",
+			Destination: &flagIncludeHandled,
+		},
+	}
+
+	flagsOnlyBaselineCreateMode := []cli.Flag{
+		&cli.StringFlag{ //nolint: exhaustruct
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Value:       "",
+			Usage:       "path to the baseline file to write (default: " + baseline.DefaultFileName + " in scanned directory)",
+			Destination: &flagOutput,
+		},
+	}
+
+	applyDetectorBinaries := func(cCtx *cli.Context, configFile configfile.ConfigFile) {
+		if configFile.DetectorBinaries != nil && !cCtx.IsSet("detector-binary") {
+			dependencies.SetBinaryChoices(configFile.DetectorBinaries)
+		}
+	}
+
+	// Values from config files only apply to flags that have been set neither
+	// on the command line nor through environment variables.
+	applyConfigFile := func(cCtx *cli.Context, configFile configfile.ConfigFile) error {
+		if configFile.DisabledDetectors != nil && !cCtx.IsSet("disabled-detectors") {
+			flagDisabledDetectors = configFile.DisabledDetectors
+		}
+		if configFile.Tolerance != nil && !cCtx.IsSet("tolerance") {
+			flagTolerance = *configFile.Tolerance
+		}
+		if configFile.Format != "" && !cCtx.IsSet("format") {
+			flagFormat = configFile.Format
+		}
+		if configFile.MinSeverity != "" && !cCtx.IsSet("min-severity") {
+			flagMinSeverity = configFile.MinSeverity
+		}
+		if configFile.FailOn != "" && !cCtx.IsSet("fail-on") {
+			flagFailOn = configFile.FailOn
+		}
+		if configFile.Timeout != "" && !cCtx.IsSet("timeout") {
+			timeout, err := time.ParseDuration(configFile.Timeout)
+			if err != nil {
+				return err
+			}
+			flagTimeout = timeout
+		}
+		if configFile.DetectorTimeouts != nil && !cCtx.IsSet("detector-timeout") {
+			flagDetectorTimeouts = make([]string, 0)
+			for detectorName, timeout := range configFile.DetectorTimeouts {
+				flagDetectorTimeouts = append(flagDetectorTimeouts, detectorName+"="+timeout)
+			}
+		}
+		if configFile.CustomRulePaths != nil && !cCtx.IsSet("custom-rules") {
+			flagCustomRules = configFile.CustomRulePaths
+		}
+		applyDetectorBinaries(cCtx, configFile)
+
+		return applyServerUrls(cCtx, configFile, false)
 	}
 
 	directoryToScan := "."
@@ -71,23 +359,93 @@ func main() { //nolint: funlen, cyclop
 			return errors.New("too many arguments")
 		}
 
+		configFile, err := configfile.Load(directoryToScan)
+		if err != nil {
+			return err
+		}
+
+		err = applyConfigFile(cCtx, configFile)
+		if err != nil {
+			return err
+		}
+
+		err = detectors.ValidateDetectorNames(flagDisabledDetectors)
+		if err != nil {
+			return err
+		}
+
+		customRulePaths, err := functional.MapWithError(flagCustomRules, filepath.Abs)
+		if err != nil {
+			return err
+		}
+
+		if flagSince != "" && flagStaged {
+			return errors.New("--since and --staged cannot be combined")
+		}
+
+		scanOptions := types.ScanOptions{
+			DirectoryToScan: directoryToScan,
+			GitMode:         flagGitMode,
+			Since:           flagSince,
+			Staged:          flagStaged,
+			ChangedFiles:    nil,
+			CustomRulePaths: customRulePaths,
+		}
+
+		detectorTimeouts, err := scan.ParseDetectorTimeouts(flagDetectorTimeouts)
+		if err != nil {
+			return err
+		}
+		timeouts := scan.Timeouts{
+			Scan:      flagTimeout,
+			Detectors: detectorTimeouts,
+		}
+
 		switch cCtx.Command.Name {
 		case "scan":
 			{
-				if flagFormat != "text" && flagFormat != "json" {
+				if flagFormat != "text" && flagFormat != "json" && flagFormat != "sarif" {
 					return errors.New("unsupported value for --format")
 				}
-				printAsJson := flagFormat == "json"
 
-				err := scan.CommandScan(directoryToScan, flagGitMode, flagDisabledDetectors,
-					printAsJson, flagOutput, flagTolerance)
+				minSeverity, err := types.ParseSeverity(flagMinSeverity)
+				if err != nil {
+					return err
+				}
+
+				failOn, err := types.ParseSeverity(flagFailOn)
+				if err != nil {
+					return err
+				}
+
+				commandScanOptions := scan.CommandScanOptions{
+					BaselinePath:      flagBaseline,
+					Format:            flagFormat,
+					OutputDestination: flagOutput,
+					Tolerance:         flagTolerance,
+					MinSeverity:       minSeverity,
+					FailOn:            failOn,
+					ShowSecrets:       flagShowSecrets,
+				}
+
+				err = scan.CommandScan(cCtx.Context, scanOptions, flagDisabledDetectors,
+					timeouts, commandScanOptions)
 				if err != nil {
 					return err
 				}
 			}
 		case "fix":
 			{
-				err := fix.CommandFix(directoryToScan, flagGitMode, flagDisabledDetectors)
+				err := fix.CommandFix(cCtx.Context, scanOptions, flagDisabledDetectors, timeouts,
+					flagIncludeHandled)
+				if err != nil {
+					return err
+				}
+			}
+		case "create": // baseline create
+			{
+				err := scan.CommandBaselineCreate(cCtx.Context, scanOptions,
+					flagDisabledDetectors, timeouts, flagOutput)
 				if err != nil {
 					return err
 				}
@@ -102,12 +460,158 @@ func main() { //nolint: funlen, cyclop
 	}
 
 	app := &cli.App{ //nolint: exhaustruct
+		Version: config.Version,
+		Flags: []cli.Flag{
+			&cli.StringFlag{ //nolint: exhaustruct
+				Name:        "profile",
+				Value:       profile.DefaultProfileName,
+				Usage:       "name of the profile to use for logins and reports (e.g. for multiple secguro servers)",
+				EnvVars:     []string{"SECGURO_PROFILE"},
+				Destination: &flagProfile,
+			},
+			&cli.StringFlag{ //nolint: exhaustruct
+				Name:        "server-url",
+				Value:       config.DefaultServerUrl,
+				Usage:       "base URL of the secguro server",
+				EnvVars:     []string{"SECGURO_SERVER_URL"},
+				Destination: &flagServerUrl,
+			},
+			&cli.StringFlag{ //nolint: exhaustruct
+				Name:        "webapp-url",
+				Value:       config.DefaultWebappUrl,
+				Usage:       "base URL of the secguro webapp",
+				EnvVars:     []string{"SECGURO_WEBAPP_URL"},
+				Destination: &flagWebappUrl,
+			},
+			&cli.DurationFlag{ //nolint: exhaustruct
+				Name:        "http-timeout",
+				Value:       config.HttpDefaultTimeout,
+				Usage:       "timeout of a single request to the secguro server",
+				EnvVars:     []string{"SECGURO_HTTP_TIMEOUT"},
+				Destination: &flagHttpTimeout,
+			},
+			&cli.IntFlag{ //nolint: exhaustruct
+				Name:        "http-retries",
+				Value:       config.HttpDefaultMaxRetries,
+				Usage:       "number of retries of failed requests to the secguro server",
+				EnvVars:     []string{"SECGURO_HTTP_RETRIES"},
+				Destination: &flagHttpRetries,
+			},
+			&cli.StringFlag{ //nolint: exhaustruct
+				Name:        "ca-bundle",
+				Value:       "",
+				Usage:       "path to a PEM file of additional CA certificates to trust (e.g. of a TLS-intercepting proxy)",
+				EnvVars:     []string{"SECGURO_CA_BUNDLE"},
+				Destination: &flagCaBundle,
+			},
+			&cli.BoolFlag{ //nolint: exhaustruct
+				Name:        "verbose",
+				Value:       false,
+				Usage:       "log detector durations and failures to stderr",
+				EnvVars:     []string{"SECGURO_VERBOSE"},
+				Destination: &flagVerbose,
+			},
+			&cli.BoolFlag{ //nolint: exhaustruct
+				Name:        "debug",
+				Value:       false,
+				Usage:       "like --verbose, additionally log the command lines, durations and exit codes of external programs",
+				EnvVars:     []string{"SECGURO_DEBUG"},
+				Destination: &flagDebug,
+			},
+			&cli.BoolFlag{ //nolint: exhaustruct
+				Name:        "quiet",
+				Aliases:     []string{"q"},
+				Value:       false,
+				Usage:       "only log warnings to stderr (no progress)",
+				EnvVars:     []string{"SECGURO_QUIET"},
+				Destination: &flagQuiet,
+			},
+			&cli.BoolFlag{ //nolint: exhaustruct
+				Name:        "offline",
+				Value:       false,
+				Usage:       "never access the network; detectors are only loaded from a bundle (see secguro deps bundle)",
+				EnvVars:     []string{"SECGURO_OFFLINE"},
+				Destination: &flagOffline,
+			},
+			&cli.StringFlag{ //nolint: exhaustruct
+				Name:        "bundle",
+				Value:       "",
+				Usage:       "path to a bundle created by secguro deps bundle to import detectors from",
+				EnvVars:     []string{"SECGURO_BUNDLE"},
+				Destination: &flagBundle,
+			},
+			&cli.MultiStringFlag{
+				Target: &cli.StringSliceFlag{ //nolint: exhaustruct
+					Name: "detector-binary",
+					Usage: "where to take the binary of a detector from: auto (PATH if recent enough, otherwise " +
+						"download), path, managed or the path of the binary (e.g. gitleaks=/usr/bin/gitleaks)",
+					EnvVars: []string{"SECGURO_DETECTOR_BINARIES"},
+				},
+				Value:       []string{},
+				Destination: &flagDetectorBinaries,
+			},
+		},
+		Before: func(cCtx *cli.Context) error {
+			switch {
+			case flagQuiet && (flagVerbose || flagDebug):
+				return errors.New("--quiet cannot be combined with --verbose or --debug")
+			case flagDebug:
+				logging.SetLevel(logging.LevelDebug)
+			case flagVerbose:
+				logging.SetLevel(logging.LevelVerbose)
+			case flagQuiet:
+				logging.SetLevel(logging.LevelQuiet)
+			}
+
+			config.Offline = flagOffline
+			config.BundlePath = flagBundle
+
+			binaryChoices, err := dependencies.ParseBinaryChoices(flagDetectorBinaries)
+			if err != nil {
+				return err
+			}
+			dependencies.SetBinaryChoices(binaryChoices)
+
+			err = api.Configure(api.ClientOptions{
+				Timeout:      flagHttpTimeout,
+				MaxRetries:   flagHttpRetries,
+				CaBundlePath: flagCaBundle,
+			})
+			if err != nil {
+				return err
+			}
+
+			return profile.SetCurrent(flagProfile)
+		},
 		Commands: []*cli.Command{
 			{
-				Name:   "login",
-				Usage:  "log in to report findings to secguro web",
+				Name:  "login",
+				Usage: "log in to report findings to secguro web",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{ //nolint: exhaustruct
+						Name:        "expiry",
+						Value:       config.DeviceRegistrationDefaultExpiry,
+						Usage:       "time to wait for the device to be registered",
+						Destination: &flagExpiry,
+					},
+					&cli.BoolFlag{ //nolint: exhaustruct
+						Name:        "open",
+						Usage:       "open the registration link in the browser",
+						Destination: &flagOpen,
+					},
+				},
 				Action: loginAction,
 			},
+			{
+				Name:   "logout",
+				Usage:  "revoke and delete the device token",
+				Action: logoutAction,
+			},
+			{
+				Name:   "whoami",
+				Usage:  "show the logged in device and the server it belongs to",
+				Action: whoamiAction,
+			},
 			{
 				Name:   "scan",
 				Usage:  "scan for problems",
@@ -117,16 +621,179 @@ func main() { //nolint: funlen, cyclop
 			{
 				Name:   "fix",
 				Usage:  "scan for problems and then switch to an interactive mode to fix them",
-				Flags:  flagsScanAndFixMode,
+				Flags:  append(append([]cli.Flag{}, flagsScanAndFixMode...), flagsOnlyFixMode...),
 				Action: scanOrFixAction,
 			},
+			{
+				Name:  "install-hooks",
+				Usage: "install a git pre-commit hook that prevents committing secrets",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{ //nolint: exhaustruct
+						Name:        "uninstall",
+						Usage:       "remove the pre-commit hook installed by secguro",
+						Destination: &flagUninstall,
+					},
+				},
+				Action: installHooksAction,
+			},
+			{
+				Name:   "pre-commit",
+				Usage:  "scan staged changes for secrets (run by the pre-commit hook)",
+				Hidden: true,
+				Action: preCommitAction,
+			},
+			{
+				Name:  "report",
+				Usage: "manage scan reports",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "flush",
+						Usage:  "send scan reports that could not be sent before",
+						Action: reportFlushAction,
+					},
+				},
+			},
+			{
+				Name:  "profile",
+				Usage: "manage profiles",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "list profiles and the servers they belong to",
+						Action: profileListAction,
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "manage config files (" + configfile.ProjectConfigFileName + " and ~/.secguro/config.yaml)",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "validate",
+						Usage:  "check the config files that apply to the given directory",
+						Action: configValidateAction,
+					},
+				},
+			},
+			{
+				Name:  "deps",
+				Usage: "manage the detectors and tools that secguro downloads",
+				Before: func(cCtx *cli.Context) error {
+					configFile, err := configfile.Load(".")
+					if err != nil {
+						return err
+					}
+
+					applyDetectorBinaries(cCtx, configFile)
+
+					return nil
+				},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "list the installed version of each dependency and where it came from",
+						Action: func(cCtx *cli.Context) error {
+							return dependencies.CommandList()
+						},
+					},
+					{
+						Name:      "install",
+						Usage:     "install dependencies (all if none are given), e.g. to prepare CI images",
+						ArgsUsage: "[name...]",
+						Flags: []cli.Flag{
+							&cli.MultiStringFlag{
+								Target: &cli.StringSliceFlag{ //nolint: exhaustruct
+									Name:  "version",
+									Usage: "version to install and use instead of the pinned one (e.g. gitleaks=8.19.0)",
+								},
+								Value:       []string{},
+								Destination: &flagDependencyVersions,
+							},
+							&cli.MultiStringFlag{
+								Target: &cli.StringSliceFlag{ //nolint: exhaustruct
+									Name:  "checksum",
+									Usage: "SHA-256 checksum of the download for this platform of a version given with --version (e.g. gitleaks=<sha256>)",
+								},
+								Value:       []string{},
+								Destination: &flagDependencyChecksums,
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							return dependencies.CommandInstall(cCtx.Args().Slice(), flagDependencyVersions,
+								flagDependencyChecksums)
+						},
+					},
+					{
+						Name:      "update",
+						Usage:     "switch dependencies (all if none are given) to the pinned versions and remove other versions",
+						ArgsUsage: "[name...]",
+						Action: func(cCtx *cli.Context) error {
+							return dependencies.CommandUpdate(cCtx.Args().Slice())
+						},
+					},
+					{
+						Name:  "clean",
+						Usage: "remove unused versions of dependencies and leftovers of interrupted downloads",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{ //nolint: exhaustruct
+								Name:        "all",
+								Value:       false,
+								Usage:       "remove all dependencies and detector data",
+								Destination: &flagCleanAll,
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							return dependencies.CommandClean(flagCleanAll)
+						},
+					},
+					{
+						Name:  "verify",
+						Usage: "check installed dependencies against their checksums",
+						Action: func(cCtx *cli.Context) error {
+							return dependencies.CommandVerify()
+						},
+					},
+					{
+						Name:  "bundle",
+						Usage: "create a bundle of all detectors and their data for use with --offline",
+						Flags: []cli.Flag{
+							&cli.StringFlag{ //nolint: exhaustruct
+								Name:        "output",
+								Aliases:     []string{"o"},
+								Value:       "",
+								Usage:       "path of the bundle (default: secguro-bundle-<os>-<arch>.tar.gz)",
+								Destination: &flagBundleOutput,
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							return dependencies.CommandBundle(flagBundleOutput)
+						},
+					},
+				},
+			},
+			{
+				Name:  "baseline",
+				Usage: "manage baselines of known findings",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "create",
+						Usage:  "scan for problems and record all findings in a baseline file",
+						Flags:  append(append([]cli.Flag{}, flagsScanAndFixMode...), flagsOnlyBaselineCreateMode...),
+						Action: scanOrFixAction,
+					},
+				},
+			},
 		},
 		Action: func(cCtx *cli.Context) error {
 			return errors.New("no command or invalid command provided")
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
+	// Cancel running detectors (and kill their processes) on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := app.RunContext(ctx, os.Args)
+	stop()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/secguro/secguro-cli/pkg/config"
+)
+
+const authProvider = "secguro"
+
+type ClientOptions struct {
+	Timeout      time.Duration // per attempt
+	MaxRetries   int           // number of retries after the first attempt
+	CaBundlePath string        // PEM file with certificates trusted in addition to the system ones
+}
+
+var clientOptions = ClientOptions{
+	Timeout:      config.HttpDefaultTimeout,
+	MaxRetries:   config.HttpDefaultMaxRetries,
+	CaBundlePath: "",
+}
+
+// Applies to all requests made afterwards.
+func Configure(options ClientOptions) error {
+	if options.Timeout < 0 {
+		return errors.New("HTTP timeout must not be negative")
+	}
+
+	if options.MaxRetries < 0 {
+		return errors.New("number of HTTP retries must not be negative")
+	}
+
+	if options.CaBundlePath != "" {
+		_, err := getRootCertificates(options.CaBundlePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	clientOptions = options
+
+	return nil
+}
+
+// Proxies are taken from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+func newClient() (*resty.Client, error) {
+	client := resty.New().
+		SetBaseURL(config.ServerUrl).
+		SetHeader("User-Agent", "secguro-cli/"+config.Version).
+		SetHeader("Content-Type", "application/json").
+		SetTimeout(clientOptions.Timeout).
+		SetRetryCount(clientOptions.MaxRetries).
+		SetRetryWaitTime(config.HttpRetryWaitTime).
+		SetRetryMaxWaitTime(config.HttpRetryMaxWaitTime)
+
+	if clientOptions.CaBundlePath != "" {
+		rootCertificates, err := getRootCertificates(clientOptions.CaBundlePath)
+		if err != nil {
+			return nil, err
+		}
+
+		client.SetTLSClientConfig(&tls.Config{ //nolint: exhaustruct
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    rootCertificates,
+		})
+	}
+
+	return client, nil
+}
+
+// Adds the certificates of the bundle to the system certificates.
+func getRootCertificates(caBundlePath string) (*x509.CertPool, error) {
+	rootCertificates, err := x509.SystemCertPool()
+	if err != nil {
+		rootCertificates = x509.NewCertPool()
+	}
+
+	caBundle, err := os.ReadFile(caBundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rootCertificates.AppendCertsFromPEM(caBundle) {
+		return nil, errors.New("no certificates found in CA bundle " + caBundlePath)
+	}
+
+	return rootCertificates, nil
+}
+
+/**
+ * Requests are retried with exponential backoff on network errors, server errors and rate
+ * limiting. Requests with methods that are not idempotent (e.g. POST devices or POST scans)
+ * are only retried if they cannot have been processed by the server: if the connection could
+ * not be established or the server declined the request because of rate limiting. Otherwise,
+ * retries could create duplicate devices or scans.
+ */
+func shouldRetry(method string, response *resty.Response, err error) bool {
+	if isIdempotentMethod(method) {
+		return err != nil || (response != nil && isRetryableStatusCode(response.StatusCode()))
+	}
+
+	if err != nil {
+		return isConnectionError(err)
+	}
+
+	return response != nil && response.StatusCode() == http.StatusTooManyRequests
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// Whether the request has failed before it could be sent.
+func isConnectionError(err error) bool {
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return true
+	}
+
+	var opError *net.OpError
+
+	return errors.As(err, &opError) && (opError.Op == "dial" || opError.Op == "proxyconnect")
+}
+
+func isRetryableStatusCode(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError ||
+		statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+}
+
+/**
+ * Sends a JSON request to the secguro server and decodes the JSON response into a
+ * value of type T. Status codes other than the expected ones result in an *Error.
+ */
+func request[T any](ctx context.Context, method string, path string, authToken string,
+	body any, expectedStatusCodes ...int) (T, error) {
+	return doRequest[T](ctx, method, path, authToken, body, false, expectedStatusCodes...)
+}
+
+// Like request but sends the body gzip-compressed.
+func requestGzipped[T any](ctx context.Context, method string, path string, authToken string,
+	body any, expectedStatusCodes ...int) (T, error) {
+	return doRequest[T](ctx, method, path, authToken, body, true, expectedStatusCodes...)
+}
+
+func doRequest[T any](ctx context.Context, method string, path string, authToken string,
+	body any, compress bool, expectedStatusCodes ...int) (T, error) {
+	var result T
+
+	if config.Offline {
+		return result, ErrOffline
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return result, err
+	}
+
+	req := client.R().
+		SetContext(ctx).
+		SetResult(&result).
+		AddRetryCondition(func(response *resty.Response, err error) bool {
+			return shouldRetry(method, response, err)
+		})
+
+	if authToken != "" {
+		req.SetHeader("Authorization", authProvider+" "+authToken)
+	}
+
+	if body != nil && compress {
+		compressedBody, err := getGzippedJson(body)
+		if err != nil {
+			return result, err
+		}
+
+		req.SetHeader("Content-Encoding", "gzip")
+		req.SetBody(compressedBody)
+	} else if body != nil {
+		req.SetBody(body)
+	}
+
+	response, err := req.Execute(method, path)
+	if err != nil {
+		return result, err
+	}
+
+	for _, expectedStatusCode := range expectedStatusCodes {
+		if response.StatusCode() == expectedStatusCode {
+			return result, nil
+		}
+	}
+
+	return result, newError(method, path, response)
+}
+
+func getGzippedJson(body any) ([]byte, error) {
+	bodyJson, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err = writer.Write(bodyJson)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestRetriesNonIdempotentRequestsIfConnectionFails(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverUrl := "http://" + listener.Addr().String()
+	listener.Close()
+
+	_, err = resty.New().R().Post(serverUrl)
+	if err == nil {
+		t.Fatal("expected connection error")
+	}
+
+	if !shouldRetry(http.MethodPost, nil, err) {
+		t.Fatalf("expected POST to be retried after connection error %v", err)
+	}
+}
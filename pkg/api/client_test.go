@@ -0,0 +1,215 @@
+package api_test
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/secguro/secguro-cli/pkg/api"
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+// As in the api package.
+const maxErrorMessageLength = 300
+
+/**
+ * Points the client at the server with the given options until the end of the test. As the
+ * client is configured for the whole process, tests that use it cannot run in parallel.
+ */
+func setUpClient(t *testing.T, serverUrl string, options api.ClientOptions) {
+	t.Helper()
+
+	previousServerUrl := config.ServerUrl
+	t.Cleanup(func() {
+		config.ServerUrl = previousServerUrl
+		_ = api.Configure(api.ClientOptions{
+			Timeout:      config.HttpDefaultTimeout,
+			MaxRetries:   config.HttpDefaultMaxRetries,
+			CaBundlePath: "",
+		})
+	})
+
+	config.ServerUrl = serverUrl
+
+	err := api.Configure(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Responds with the given status codes in turn (the last one repeatedly) and counts the requests.
+func newServer(t *testing.T, statusCodes []int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requestCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		index := int(requestCount.Add(1)) - 1
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCodes[min(index, len(statusCodes)-1)])
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requestCount
+}
+
+func TestRetriesIdempotentRequestsOnServerErrors(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	server, requestCount := newServer(t, []int{http.StatusServiceUnavailable, http.StatusOK},
+		`{"IsRegistered":true}`)
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 2, CaBundlePath: ""})
+
+	result, err := api.GetDeviceRegistration(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.IsRegistered || requestCount.Load() != 2 {
+		t.Fatalf("expected success on the second attempt, got %v after %d attempts", result, requestCount.Load())
+	}
+}
+
+func TestDoesNotRetryNonIdempotentRequestsOnServerErrors(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	server, requestCount := newServer(t, []int{http.StatusBadGateway}, `{"Message":"bad gateway"}`)
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 2, CaBundlePath: ""})
+
+	_, err := api.PostDevice(context.Background(), types.DevicePostReq{DeviceName: "test"})
+
+	var apiError *api.Error
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected *Error with status code 502, got %v", err)
+	}
+
+	if requestCount.Load() != 1 {
+		t.Fatalf("expected 1 attempt, got %d", requestCount.Load())
+	}
+}
+
+func TestRetriesNonIdempotentRequestsOnRateLimiting(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	server, requestCount := newServer(t, []int{http.StatusTooManyRequests, http.StatusCreated},
+		`{"ID":7,"DeviceToken":"token"}`)
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 2, CaBundlePath: ""})
+
+	result, err := api.PostDevice(context.Background(), types.DevicePostReq{DeviceName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ID != 7 || requestCount.Load() != 2 {
+		t.Fatalf("expected success on the second attempt, got %v after %d attempts", result, requestCount.Load())
+	}
+}
+
+func TestTimesOutEachAttempt(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	var requestCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: 100 * time.Millisecond, MaxRetries: 1, CaBundlePath: ""})
+
+	_, err := api.GetDeviceRegistration(context.Background(), 1)
+	if err == nil {
+		t.Fatal("expected timeout")
+	}
+	if requestCount.Load() != 2 {
+		t.Fatalf("expected the GET request to be retried once, got %d attempts", requestCount.Load())
+	}
+
+	requestCount.Store(0)
+
+	// The server may have processed the request even though the response did not arrive in time.
+	_, err = api.PostDevice(context.Background(), types.DevicePostReq{DeviceName: "test"})
+	if err == nil {
+		t.Fatal("expected timeout")
+	}
+	if requestCount.Load() != 1 {
+		t.Fatalf("expected the POST request not to be retried, got %d attempts", requestCount.Load())
+	}
+}
+
+func TestDecodesErrorMessages(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	testCases := []struct {
+		body            string
+		expectedMessage string
+	}{
+		{`{"Message":"device not found"}`, "device not found"},
+		{`{"Error":"invalid token"}`, "invalid token"},
+		{"plain text\n", "plain text"},
+		{strings.Repeat("x", maxErrorMessageLength+1), strings.Repeat("x", maxErrorMessageLength) + "..."},
+	}
+
+	for _, testCase := range testCases {
+		server, _ := newServer(t, []int{http.StatusUnauthorized}, testCase.body)
+		setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 0, CaBundlePath: ""})
+
+		err := api.DeleteDevice(context.Background(), "token", 1)
+
+		var apiError *api.Error
+		if !errors.As(err, &apiError) {
+			t.Fatalf("expected *Error, got %v", err)
+		}
+		if apiError.Message != testCase.expectedMessage || !apiError.IsUnauthorized() || apiError.IsRetryable() {
+			t.Fatalf("unexpected error for body %q: %#v", testCase.body, apiError)
+		}
+		if apiError.Method != http.MethodDelete || apiError.Path != "devices/1" {
+			t.Fatalf("unexpected request in error: %v", apiError)
+		}
+	}
+}
+
+func TestTrustsCaBundle(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"IsRegistered":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	// The certificate of the test server is not trusted by the system.
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 0, CaBundlePath: ""})
+	_, err := api.GetDeviceRegistration(context.Background(), 1)
+	if err == nil {
+		t.Fatal("expected certificate verification to fail without CA bundle")
+	}
+
+	caBundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err = os.WriteFile(caBundlePath, caBundle, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 0, CaBundlePath: caBundlePath})
+	result, err := api.GetDeviceRegistration(context.Background(), 1)
+	if err != nil || !result.IsRegistered {
+		t.Fatalf("expected request to succeed with CA bundle, got %v (%v)", result, err)
+	}
+}
+
+func TestRejectsCaBundleWithoutCertificates(t *testing.T) {
+	t.Parallel()
+
+	caBundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caBundlePath, []byte("no certificates"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Configure(api.ClientOptions{Timeout: time.Second, MaxRetries: 0, CaBundlePath: caBundlePath})
+	if err == nil {
+		t.Fatal("expected error for CA bundle without certificates")
+	}
+}
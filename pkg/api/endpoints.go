@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+func PostDevice(ctx context.Context, devicePostReq types.DevicePostReq) (types.DevicePostRes, error) {
+	return request[types.DevicePostRes](ctx, http.MethodPost, "devices", "",
+		devicePostReq, http.StatusCreated)
+}
+
+func GetDeviceRegistration(ctx context.Context, deviceId uint) (types.DeviceRegistrationRes, error) {
+	return request[types.DeviceRegistrationRes](ctx, http.MethodGet,
+		fmt.Sprintf("devices/%d/registrations", deviceId), "", nil, http.StatusOK)
+}
+
+// Also succeeds if the device does not exist (anymore).
+func DeleteDevice(ctx context.Context, authToken string, deviceId uint) error {
+	_, err := request[any](ctx, http.MethodDelete, fmt.Sprintf("devices/%d", deviceId), authToken,
+		nil, http.StatusOK, http.StatusNoContent, http.StatusNotFound)
+
+	return err
+}
+
+/**
+ * Sends the report gzip-compressed. Servers that do not support compressed requests reject
+ * them with status code 400 or 415, in which case the report is sent again uncompressed.
+ */
+func PostScan(ctx context.Context, authToken string, scanPostReq types.ScanPostReq) error {
+	result, err := requestGzipped[types.ConfirmationRes](ctx, http.MethodPost, "scans", authToken,
+		scanPostReq, http.StatusCreated)
+
+	var apiError *Error
+	if errors.As(err, &apiError) &&
+		(apiError.StatusCode == http.StatusBadRequest || apiError.StatusCode == http.StatusUnsupportedMediaType) {
+		result, err = request[types.ConfirmationRes](ctx, http.MethodPost, "scans", authToken,
+			scanPostReq, http.StatusCreated)
+	}
+	if err != nil {
+		return err
+	}
+
+	return checkConfirmation(result)
+}
+
+// Starts an upload of a scan report whose findings are sent in batches.
+func PostScanUpload(ctx context.Context, authToken string,
+	scanUploadPostReq types.ScanUploadPostReq) (types.ScanUploadRes, error) {
+	return requestGzipped[types.ScanUploadRes](ctx, http.MethodPost, "scans/uploads", authToken,
+		scanUploadPostReq, http.StatusCreated)
+}
+
+func PostScanUploadFindings(ctx context.Context, authToken string, uploadId uint,
+	scanUploadFindingsPostReq types.ScanUploadFindingsPostReq) error {
+	_, err := requestGzipped[any](ctx, http.MethodPost, fmt.Sprintf("scans/uploads/%d/findings", uploadId),
+		authToken, scanUploadFindingsPostReq, http.StatusOK, http.StatusCreated)
+
+	return err
+}
+
+// Creates the scan from the uploaded findings.
+func PostScanUploadCommit(ctx context.Context, authToken string, uploadId uint) error {
+	result, err := request[types.ConfirmationRes](ctx, http.MethodPost,
+		fmt.Sprintf("scans/uploads/%d/commit", uploadId), authToken, nil, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+
+	return checkConfirmation(result)
+}
+
+func checkConfirmation(confirmationRes types.ConfirmationRes) error {
+	if confirmationRes.Status != "created" {
+		return errors.New("received bad status response")
+	}
+
+	return nil
+}
+
+func PostDependencycheckScan(ctx context.Context,
+	dependencycheckScanPostReq types.DependencycheckScanPostReq) (types.DependencycheckScanRes, error) {
+	return request[types.DependencycheckScanRes](ctx, http.MethodPost, "dependencycheckScans", "",
+		dependencycheckScanPostReq, http.StatusOK)
+}
+
+func PostFixedFileContent(ctx context.Context,
+	fixedFileContentPostReq types.FixedFileContentPostReq) (types.FixedFileContentRes, error) {
+	return request[types.FixedFileContentRes](ctx, http.MethodPost, "fixedFileContents", "",
+		fixedFileContentPostReq, http.StatusOK)
+}
@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/secguro/secguro-cli/pkg/api"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+// Behaves like a server that does not support compressed requests.
+func newServerWithoutCompression(t *testing.T, statusCodeUncompressed int) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	contentEncodings := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentEncodings = append(contentEncodings, r.Header.Get("Content-Encoding"))
+		if r.Header.Get("Content-Encoding") != "" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+
+			return
+		}
+
+		var scanPostReq types.ScanPostReq
+		err := json.NewDecoder(r.Body).Decode(&scanPostReq)
+		if err != nil || scanPostReq.AssetName != "asset" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCodeUncompressed)
+		_, _ = w.Write([]byte(`{"Status":"created"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &contentEncodings
+}
+
+func TestPostScanSendsReportGzipped(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		body, err := io.ReadAll(reader)
+		if err != nil || r.Header.Get("Content-Encoding") != "gzip" || !json.Valid(body) {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"Status":"created"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 0, CaBundlePath: ""})
+
+	err := api.PostScan(context.Background(), "token", types.ScanPostReq{AssetName: "asset"}) //nolint: exhaustruct
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostScanFallsBackToUncompressedReport(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	server, contentEncodings := newServerWithoutCompression(t, http.StatusCreated)
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 0, CaBundlePath: ""})
+
+	err := api.PostScan(context.Background(), "token", types.ScanPostReq{AssetName: "asset"}) //nolint: exhaustruct
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*contentEncodings) != 2 || (*contentEncodings)[0] != "gzip" || (*contentEncodings)[1] != "" {
+		t.Fatalf("expected a compressed and then an uncompressed request, got %q", *contentEncodings)
+	}
+}
+
+func TestPostScanReturnsErrorOfUncompressedReport(t *testing.T) { //nolint: paralleltest // uses setUpClient
+	server, _ := newServerWithoutCompression(t, http.StatusUnprocessableEntity)
+	setUpClient(t, server.URL, api.ClientOptions{Timeout: time.Second, MaxRetries: 0, CaBundlePath: ""})
+
+	err := api.PostScan(context.Background(), "token", types.ScanPostReq{AssetName: "asset"}) //nolint: exhaustruct
+
+	var apiError *api.Error
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected *Error with status code 422, got %v", err)
+	}
+}
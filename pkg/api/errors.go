@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const maxErrorMessageLength = 300
+
+var ErrOffline = errors.New("cannot reach the secguro server in offline mode")
+
+// Returned for responses with unexpected status codes.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string // message sent by the server; may be empty
+}
+
+func (e *Error) Error() string {
+	description := fmt.Sprintf("server responded to %s %s with status code %d", e.Method, e.Path, e.StatusCode)
+	if e.Message == "" {
+		return description
+	}
+
+	return description + ": " + e.Message
+}
+
+// Whether sending the same request again might succeed.
+func (e *Error) IsRetryable() bool {
+	return isRetryableStatusCode(e.StatusCode)
+}
+
+func (e *Error) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+func (e *Error) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+func newError(method string, path string, response *resty.Response) *Error {
+	return &Error{
+		Method:     method,
+		Path:       path,
+		StatusCode: response.StatusCode(),
+		Message:    getErrorMessage(response.Body()),
+	}
+}
+
+// Extracts the message from JSON error responses and falls back to the plain body.
+func getErrorMessage(body []byte) string {
+	var errorRes struct {
+		Message string
+		Error   string
+	}
+
+	message := ""
+	err := json.Unmarshal(body, &errorRes)
+	if err == nil {
+		message = errorRes.Message
+		if message == "" {
+			message = errorRes.Error
+		}
+	} else {
+		message = strings.TrimSpace(string(body))
+	}
+
+	if len(message) > maxErrorMessageLength {
+		message = message[:maxErrorMessageLength] + "..."
+	}
+
+	return message
+}
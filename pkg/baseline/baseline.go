@@ -0,0 +1,91 @@
+package baseline
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/secguro/secguro-cli/pkg/fingerprint"
+	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+const DefaultFileName = ".secguro-baseline.json"
+
+const fileFormatVersion = 1
+
+type Baseline struct {
+	Version  int
+	Findings []BaselineFinding
+}
+
+type BaselineFinding struct {
+	Fingerprint string
+	Detector    string
+	Rule        string
+	File        string
+}
+
+func CreateBaseline(unifiedFindings []types.UnifiedFinding) Baseline {
+	return Baseline{
+		Version: fileFormatVersion,
+		Findings: functional.Map(unifiedFindings, func(unifiedFinding types.UnifiedFinding) BaselineFinding {
+			return BaselineFinding{
+				Fingerprint: unifiedFinding.Fingerprint,
+				Detector:    unifiedFinding.Detector,
+				Rule:        unifiedFinding.Rule,
+				File:        fingerprint.GetFile(unifiedFinding),
+			}
+		}),
+	}
+}
+
+func WriteBaseline(path string, baseline Baseline) error {
+	baselineJson, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	const filePermissions = 0644
+
+	return os.WriteFile(path, append(baselineJson, '\n'), filePermissions)
+}
+
+func ReadBaseline(path string) (Baseline, error) {
+	baselineJson, err := os.ReadFile(path)
+	if err != nil {
+		return Baseline{}, err //nolint: exhaustruct
+	}
+
+	var baseline Baseline
+	err = json.Unmarshal(baselineJson, &baseline)
+	if err != nil {
+		return Baseline{}, err //nolint: exhaustruct
+	}
+
+	if baseline.Version != fileFormatVersion {
+		return Baseline{}, errors.New("unsupported baseline file version; " + //nolint: exhaustruct
+			"please recreate it with: secguro baseline create")
+	}
+
+	return baseline, nil
+}
+
+// Returns the findings that are not recorded in the baseline. If a fingerprint occurs
+// more often than recorded in the baseline, the additional occurrences count as new.
+func GetFindingsNotInBaseline(baseline Baseline,
+	unifiedFindings []types.UnifiedFinding) []types.UnifiedFinding {
+	remainingOccurrences := make(map[string]int)
+	for _, baselineFinding := range baseline.Findings {
+		remainingOccurrences[baselineFinding.Fingerprint]++
+	}
+
+	return functional.Filter(unifiedFindings, func(unifiedFinding types.UnifiedFinding) bool {
+		if remainingOccurrences[unifiedFinding.Fingerprint] > 0 {
+			remainingOccurrences[unifiedFinding.Fingerprint]--
+			return false
+		}
+
+		return true
+	})
+}
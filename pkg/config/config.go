@@ -1,8 +1,23 @@
 package config
 
+import "time"
+
+// Set at build time via -ldflags.
+var Version = "dev"
+
+// May be overridden through flags, environment variables, config files and profiles.
+var WebappUrl = DefaultWebappUrl
+var ServerUrl = DefaultServerUrl
+
+// In offline mode, secguro never accesses the network. Detectors are only loaded from
+// the dependencies directory, into which the bundle at BundlePath (if any) is imported.
+var Offline = false
+var BundlePath = ""
+
 const CiTokenEnvVarName = "SECGURO_CI_TOKEN"
 const NvdApiKeyEnvVarName = "NVD_API_KEY"
 const OpenAiApiKeyEnvVarName = "OPEN_AI_API_KEY"
+const DependenciesDirEnvVarName = "SECGURO_DEPENDENCIES_DIR"
 
 const TolerateDependecycheckErrorExitCodes = true
 
@@ -10,3 +25,14 @@ const FileContentRelevantPartNumberOfLinesPreceding = 15
 const FileContentRelevantPartNumberOfLinesFollowing = 8
 
 const DeviceRegistrationPollingFrequencyInMs = 5 * 1000
+const DeviceRegistrationMaxPollingIntervalInMs = 60 * 1000
+const DeviceRegistrationDefaultExpiry = 10 * time.Minute
+
+// Long enough for dependencycheck scans on the server.
+const HttpDefaultTimeout = 5 * time.Minute
+const HttpDefaultMaxRetries = 3
+const HttpRetryWaitTime = 1 * time.Second
+const HttpRetryMaxWaitTime = 20 * time.Second
+
+// Reports with more findings are uploaded in batches of this size.
+const ReportFindingsBatchSize = 500
@@ -2,5 +2,5 @@
 
 package config
 
-const WebappUrl = "https://app.secguro.io"
-const ServerUrl = "https://server.secguro.io/secguro"
+const DefaultWebappUrl = "https://app.secguro.io"
+const DefaultServerUrl = "https://server.secguro.io/secguro"
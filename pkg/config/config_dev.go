@@ -2,5 +2,5 @@
 
 package config
 
-const WebappUrl = "http://localhost:3000"
-const ServerUrl = "http://localhost:8080/secguro"
+const DefaultWebappUrl = "http://localhost:3000"
+const DefaultServerUrl = "http://localhost:8080/secguro"
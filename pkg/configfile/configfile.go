@@ -0,0 +1,283 @@
+package configfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/secguro/secguro-cli/pkg/dependencies"
+	"github.com/secguro/secguro-cli/pkg/detectors"
+	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+const ProjectConfigFileName = ".secguro.yaml"
+
+// Located in the secguro config directory in the home directory.
+const userConfigFileName = "config.yaml"
+const secguroConfigDirName = ".secguro"
+
+var validFormats = []string{"text", "json", "sarif"}
+
+// Unset values are nil (or empty) so that they do not override values of other sources.
+type ConfigFile struct {
+	DisabledDetectors []string          `yaml:"disabledDetectors"`
+	Tolerance         *int              `yaml:"tolerance"`
+	Format            string            `yaml:"format"`
+	MinSeverity       string            `yaml:"minSeverity"`
+	FailOn            string            `yaml:"failOn"`
+	Timeout           string            `yaml:"timeout"`
+	DetectorTimeouts  map[string]string `yaml:"detectorTimeouts"`
+	CustomRulePaths   []string          `yaml:"customRulePaths"`
+	DetectorBinaries  map[string]string `yaml:"detectorBinaries"`
+	ServerUrl         string            `yaml:"serverUrl"`
+	WebappUrl         string            `yaml:"webappUrl"`
+}
+
+/**
+ * Loads the user config file (~/.secguro/config.yaml) and the project config file
+ * (.secguro.yaml in the directory to scan), the latter taking precedence. Missing
+ * files are skipped. Relative custom rule paths are resolved relative to the file
+ * they are specified in.
+ */
+func Load(directoryToScan string) (ConfigFile, error) {
+	configFile := ConfigFile{} //nolint: exhaustruct
+
+	configFilePaths, err := GetConfigFilePaths(directoryToScan)
+	if err != nil {
+		return configFile, err
+	}
+
+	for _, configFilePath := range configFilePaths {
+		loadedConfigFile, err := loadFile(configFilePath, isProjectConfigFilePath(configFilePath))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return configFile, err
+		}
+
+		configFile = merge(configFile, loadedConfigFile)
+	}
+
+	return configFile, nil
+}
+
+// Returns the paths of the config files in ascending order of precedence.
+func GetConfigFilePaths(directoryToScan string) ([]string, error) {
+	pathHomeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		filepath.Join(pathHomeDir, secguroConfigDirName, userConfigFileName),
+		filepath.Join(directoryToScan, ProjectConfigFileName),
+	}, nil
+}
+
+func isProjectConfigFilePath(configFilePath string) bool {
+	return filepath.Base(configFilePath) == ProjectConfigFileName
+}
+
+func loadFile(configFilePath string, isProjectConfigFile bool) (ConfigFile, error) {
+	configFile := ConfigFile{} //nolint: exhaustruct
+
+	content, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return configFile, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	// Reject unknown keys to point out typos.
+	decoder.KnownFields(true)
+	err = decoder.Decode(&configFile)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return configFile, fmt.Errorf("invalid config file %s: %w", configFilePath, err)
+	}
+
+	err = validate(configFile, isProjectConfigFile)
+	if err != nil {
+		return configFile, fmt.Errorf("invalid config file %s: %w", configFilePath, err)
+	}
+
+	configFile.CustomRulePaths = functional.Map(configFile.CustomRulePaths, func(customRulePath string) string {
+		if filepath.IsAbs(customRulePath) {
+			return customRulePath
+		}
+
+		return filepath.Join(filepath.Dir(configFilePath), customRulePath)
+	})
+
+	for detectorName, choice := range configFile.DetectorBinaries {
+		if isBinaryPath(choice) && !filepath.IsAbs(choice) {
+			configFile.DetectorBinaries[detectorName] = filepath.Join(filepath.Dir(configFilePath), choice)
+		}
+	}
+
+	return configFile, nil
+}
+
+func validate(configFile ConfigFile, isProjectConfigFile bool) error { //nolint: cyclop
+	// The project config file comes with the scanned repository, which may not be trustworthy.
+	// It must therefore not choose the server that the device token is sent to.
+	if isProjectConfigFile && (configFile.ServerUrl != "" || configFile.WebappUrl != "") {
+		return errors.New("serverUrl and webappUrl can only be set in the user config file")
+	}
+
+	// Nor must it choose binaries that secguro runs, as that would execute code from the repository.
+	if isProjectConfigFile && configFile.DetectorBinaries != nil {
+		return errors.New("detectorBinaries can only be set in the user config file")
+	}
+
+	err := detectors.ValidateDetectorNames(configFile.DisabledDetectors)
+	if err != nil {
+		return err
+	}
+
+	if configFile.Tolerance != nil && *configFile.Tolerance < 0 {
+		return errors.New("tolerance must not be negative")
+	}
+
+	if configFile.Format != "" && !functional.ArrayIncludes(validFormats, configFile.Format) {
+		return errors.New("unsupported format: " + configFile.Format)
+	}
+
+	for _, severityName := range []string{configFile.MinSeverity, configFile.FailOn} {
+		if severityName == "" {
+			continue
+		}
+
+		_, err := types.ParseSeverity(severityName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if configFile.Timeout != "" {
+		_, err := time.ParseDuration(configFile.Timeout)
+		if err != nil {
+			return errors.New("invalid timeout: " + configFile.Timeout)
+		}
+	}
+
+	for detectorName, timeout := range configFile.DetectorTimeouts {
+		err := detectors.ValidateDetectorNames([]string{detectorName})
+		if err != nil {
+			return err
+		}
+
+		_, err = time.ParseDuration(timeout)
+		if err != nil {
+			return errors.New("invalid timeout for detector " + detectorName + ": " + timeout)
+		}
+	}
+
+	return dependencies.ValidateBinaryChoices(configFile.DetectorBinaries)
+}
+
+// Choices other than auto, path and managed are paths of binaries.
+func isBinaryPath(choice string) bool {
+	return !functional.ArrayIncludes([]string{dependencies.BinaryChoiceAuto, dependencies.BinaryChoicePath,
+		dependencies.BinaryChoiceManaged}, choice)
+}
+
+// Values set in override take precedence over values set in base.
+func merge(base ConfigFile, override ConfigFile) ConfigFile {
+	result := base
+
+	if override.DisabledDetectors != nil {
+		result.DisabledDetectors = override.DisabledDetectors
+	}
+	if override.Tolerance != nil {
+		result.Tolerance = override.Tolerance
+	}
+	if override.Format != "" {
+		result.Format = override.Format
+	}
+	if override.MinSeverity != "" {
+		result.MinSeverity = override.MinSeverity
+	}
+	if override.FailOn != "" {
+		result.FailOn = override.FailOn
+	}
+	if override.Timeout != "" {
+		result.Timeout = override.Timeout
+	}
+	if override.DetectorTimeouts != nil {
+		result.DetectorTimeouts = make(map[string]string)
+		for detectorName, timeout := range base.DetectorTimeouts {
+			result.DetectorTimeouts[detectorName] = timeout
+		}
+		for detectorName, timeout := range override.DetectorTimeouts {
+			result.DetectorTimeouts[detectorName] = timeout
+		}
+	}
+	if override.CustomRulePaths != nil {
+		result.CustomRulePaths = override.CustomRulePaths
+	}
+	if override.DetectorBinaries != nil {
+		result.DetectorBinaries = make(map[string]string)
+		for detectorName, choice := range base.DetectorBinaries {
+			result.DetectorBinaries[detectorName] = choice
+		}
+		for detectorName, choice := range override.DetectorBinaries {
+			result.DetectorBinaries[detectorName] = choice
+		}
+	}
+	if override.ServerUrl != "" {
+		result.ServerUrl = override.ServerUrl
+	}
+	if override.WebappUrl != "" {
+		result.WebappUrl = override.WebappUrl
+	}
+
+	return result
+}
+
+// Validates all existing config files; returns the paths of the files that have been validated.
+func Validate(directoryToScan string) ([]string, error) {
+	configFilePaths, err := GetConfigFilePaths(directoryToScan)
+	if err != nil {
+		return nil, err
+	}
+
+	validatedConfigFilePaths := make([]string, 0)
+	for _, configFilePath := range configFilePaths {
+		_, err := loadFile(configFilePath, isProjectConfigFilePath(configFilePath))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		validatedConfigFilePaths = append(validatedConfigFilePaths, configFilePath)
+	}
+
+	return validatedConfigFilePaths, nil
+}
+
+func CommandValidate(directoryToScan string) error {
+	validatedConfigFilePaths, err := Validate(directoryToScan)
+	if err != nil {
+		return err
+	}
+
+	if len(validatedConfigFilePaths) == 0 {
+		fmt.Println("No config files found.")
+
+		return nil
+	}
+
+	for _, validatedConfigFilePath := range validatedConfigFilePaths {
+		fmt.Println("Valid: " + validatedConfigFilePath)
+	}
+
+	return nil
+}
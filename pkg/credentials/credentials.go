@@ -0,0 +1,169 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/profile"
+	"github.com/secguro/secguro-cli/pkg/utils"
+)
+
+const CredentialStoreEnvVarName = "SECGURO_CREDENTIAL_STORE"
+
+const credentialStoreNameKeyring = "keyring"
+const credentialStoreNameFile = "file"
+
+// Written by versions that stored the device token in plaintext.
+const legacyDeviceTokenFileName = "device_token"
+
+var ErrNotFound = errors.New("credentials not found")
+
+// Stores one secret per profile.
+type CredentialStore interface {
+	Name() string
+	Get(profileName string) (string, error) // returns ErrNotFound if nothing is stored
+	Set(profileName string, secret string) error
+	Delete(profileName string) error // does not fail if nothing is stored
+}
+
+type Credentials struct {
+	DeviceId    uint // 0 for credentials migrated from the legacy plaintext file
+	DeviceName  string
+	DeviceToken string
+}
+
+/**
+ * Uses the store set in SECGURO_CREDENTIAL_STORE ("keyring" or "file"). Without it,
+ * the OS keyring is used if available and the encrypted file otherwise (e.g. on
+ * headless machines without a Secret Service).
+ */
+func GetStore() (CredentialStore, error) {
+	switch os.Getenv(CredentialStoreEnvVarName) {
+	case credentialStoreNameKeyring:
+		return keyringStore{}, nil
+	case credentialStoreNameFile:
+		return fileStore{}, nil
+	case "":
+		if isKeyringAvailable() {
+			return keyringStore{}, nil
+		}
+
+		return fileStore{}, nil
+	default:
+		return nil, errors.New("unsupported value for " + CredentialStoreEnvVarName +
+			" (valid values: " + credentialStoreNameKeyring + ", " + credentialStoreNameFile + ")")
+	}
+}
+
+func Save(credentials Credentials) error {
+	store, err := GetStore()
+	if err != nil {
+		return err
+	}
+
+	credentialsJson, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	return store.Set(profile.GetCurrent(), string(credentialsJson))
+}
+
+// Returns nil if the current profile is not logged in.
+func Load() (*Credentials, error) {
+	store, err := GetStore()
+	if err != nil {
+		return nil, err
+	}
+
+	credentialsJson, err := store.Get(profile.GetCurrent())
+	if errors.Is(err, ErrNotFound) {
+		return migrateLegacyDeviceToken()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials Credentials
+	err = json.Unmarshal([]byte(credentialsJson), &credentials)
+	if err != nil {
+		return nil, errors.New("stored credentials are corrupt; please log in again")
+	}
+
+	return &credentials, nil
+}
+
+func Delete() error {
+	store, err := GetStore()
+	if err != nil {
+		return err
+	}
+
+	err = store.Delete(profile.GetCurrent())
+	if err != nil {
+		return err
+	}
+
+	legacyDeviceTokenFilePath, err := getLegacyDeviceTokenFilePath()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(legacyDeviceTokenFilePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Moves a plaintext device token into the credential store.
+func migrateLegacyDeviceToken() (*Credentials, error) {
+	legacyDeviceTokenFilePath, err := getLegacyDeviceTokenFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	doesFileExist, err := utils.DoesFileExist(legacyDeviceTokenFilePath)
+	if err != nil {
+		return nil, errors.New("cannot determine whether user is logged in")
+	}
+
+	if !doesFileExist {
+		return nil, nil //nolint: nilnil
+	}
+
+	deviceTokenBytes, err := os.ReadFile(legacyDeviceTokenFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := Credentials{
+		DeviceId:    0,
+		DeviceName:  "",
+		DeviceToken: strings.TrimSpace(string(deviceTokenBytes)),
+	}
+
+	err = Save(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Remove(legacyDeviceTokenFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentials, nil
+}
+
+func getLegacyDeviceTokenFilePath() (string, error) {
+	pathProfileDir, err := profile.GetDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return pathProfileDir + "/" + legacyDeviceTokenFileName, nil
+}
@@ -0,0 +1,258 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/profile"
+	"golang.org/x/crypto/scrypt"
+)
+
+const CredentialPassphraseEnvVarName = "SECGURO_CREDENTIAL_PASSPHRASE"
+
+// Holds the secrets of all profiles; located in the secguro config directory.
+const credentialsFileName = "credentials.enc"
+
+const credentialsFileVersion = 1
+
+const saltLength = 16
+
+// Parameters of scrypt as recommended for interactive use (about 100 ms per derivation).
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32 // for AES-256
+)
+
+var machineIdFilePaths = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+/**
+ * Stores secrets AES-256-GCM encrypted in a file (readable only by the user) with a key
+ * derived by scrypt from SECGURO_CREDENTIAL_PASSPHRASE. Only with a passphrase that is
+ * kept elsewhere (e.g. in a secret of the CI system) is the file protected against anyone
+ * who can read it. Without a passphrase, the key is derived from the machine ID and the
+ * user ID, which are not secret: this only keeps the file from being usable when copied
+ * to another machine and is no protection against other processes on the same machine.
+ */
+type fileStore struct{}
+
+type credentialsFile struct {
+	Version    int
+	Salt       []byte
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+func (fileStore) Name() string {
+	return credentialStoreNameFile
+}
+
+func (fileStore) Get(profileName string) (string, error) {
+	secrets, err := readSecrets()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[profileName]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return secret, nil
+}
+
+func (fileStore) Set(profileName string, secret string) error {
+	secrets, err := readSecrets()
+	if err != nil {
+		return err
+	}
+
+	secrets[profileName] = secret
+
+	return writeSecrets(secrets)
+}
+
+func (fileStore) Delete(profileName string) error {
+	secrets, err := readSecrets()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := secrets[profileName]; !ok {
+		return nil
+	}
+
+	delete(secrets, profileName)
+
+	return writeSecrets(secrets)
+}
+
+func getCredentialsFilePath() (string, error) {
+	pathSecguroConfigDir, err := profile.GetSecguroConfigDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(pathSecguroConfigDir, credentialsFileName), nil
+}
+
+// Returns an empty map if the file does not exist yet.
+func readSecrets() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	credentialsFilePath, err := getCredentialsFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	credentialsFileJson, err := os.ReadFile(credentialsFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file credentialsFile
+	err = json.Unmarshal(credentialsFileJson, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Version != credentialsFileVersion {
+		return nil, errors.New("unsupported credentials file version: " + strconv.Itoa(file.Version))
+	}
+
+	aead, err := getAead(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("cannot decrypt " + credentialsFilePath +
+			" (was " + CredentialPassphraseEnvVarName + " changed?)")
+	}
+
+	err = json.Unmarshal(plaintext, &secrets)
+
+	return secrets, err
+}
+
+func writeSecrets(secrets map[string]string) error {
+	credentialsFilePath, err := getCredentialsFilePath()
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltLength)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return err
+	}
+
+	aead, err := getAead(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+
+	credentialsFileJson, err := json.Marshal(credentialsFile{
+		Version:    credentialsFileVersion,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	const directoryPermissions = 0700
+	err = os.MkdirAll(filepath.Dir(credentialsFilePath), directoryPermissions)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(credentialsFilePath, credentialsFileJson)
+}
+
+// The file holds the secrets of all profiles, so it must never be left half written.
+func writeFileAtomically(filePath string, content []byte) error {
+	// os.CreateTemp creates the file with permissions 0600.
+	file, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	_, err = file.Write(content)
+	if err != nil {
+		return err
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filePath)
+}
+
+func getAead(salt []byte) (cipher.AEAD, error) {
+	passphrase, err := getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func getPassphrase() ([]byte, error) {
+	passphrase := os.Getenv(CredentialPassphraseEnvVarName)
+	if passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	for _, machineIdFilePath := range machineIdFilePaths {
+		machineId, err := os.ReadFile(machineIdFilePath)
+		if err == nil && strings.TrimSpace(string(machineId)) != "" {
+			return []byte(strings.TrimSpace(string(machineId)) + ":" + strconv.Itoa(os.Getuid())), nil
+		}
+	}
+
+	return nil, errors.New("cannot derive key for credentials file; please set " + CredentialPassphraseEnvVarName)
+}
@@ -0,0 +1,147 @@
+package credentials_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secguro/secguro-cli/pkg/credentials"
+)
+
+// As in the credentials package.
+const (
+	credentialStoreNameFile = "file"
+	credentialsFileName     = "credentials.enc"
+	credentialsFileVersion  = 1
+)
+
+type credentialsFile struct {
+	Version int
+}
+
+func getFileStore(t *testing.T) credentials.CredentialStore {
+	t.Helper()
+
+	store, err := credentials.GetStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Name() != credentialStoreNameFile {
+		t.Fatalf("expected file store, got %s", store.Name())
+	}
+
+	return store
+}
+
+// Runs the file store in a temporary home directory with the given passphrase, as on a
+// headless machine without keyring.
+func setUpFileStore(t *testing.T, passphrase string) string {
+	t.Helper()
+
+	homeDirPath := t.TempDir()
+	t.Setenv("HOME", homeDirPath)
+	t.Setenv(credentials.CredentialPassphraseEnvVarName, passphrase)
+	t.Setenv(credentials.CredentialStoreEnvVarName, credentialStoreNameFile)
+
+	return filepath.Join(homeDirPath, ".secguro")
+}
+
+func readCredentialsFile(t *testing.T, secguroConfigDirPath string) credentialsFile {
+	t.Helper()
+
+	credentialsFileJson, err := os.ReadFile(filepath.Join(secguroConfigDirPath, credentialsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file credentialsFile
+	err = json.Unmarshal(credentialsFileJson, &file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestFileStoreKeepsSecretsOfProfilesApart(t *testing.T) { //nolint: paralleltest // sets environment variables
+	setUpFileStore(t, "passphrase")
+
+	store := getFileStore(t)
+
+	for profileName, secret := range map[string]string{"default": "secret1", "onprem": "secret2"} {
+		err := store.Set(profileName, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secret, err := store.Get("onprem")
+	if err != nil || secret != "secret2" {
+		t.Fatalf("expected secret2, got %q (%v)", secret, err)
+	}
+
+	err = store.Delete("onprem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = store.Get("onprem")
+	if !errors.Is(err, credentials.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after deletion, got %v", err)
+	}
+
+	secret, err = store.Get("default")
+	if err != nil || secret != "secret1" {
+		t.Fatalf("expected secret1, got %q (%v)", secret, err)
+	}
+}
+
+func TestFileStoreRejectsOtherPassphrase(t *testing.T) { //nolint: paralleltest // sets environment variables
+	setUpFileStore(t, "passphrase")
+
+	err := getFileStore(t).Set("default", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(credentials.CredentialPassphraseEnvVarName, "other passphrase")
+
+	_, err = getFileStore(t).Get("default")
+	if err == nil {
+		t.Fatal("expected decryption with another passphrase to fail")
+	}
+}
+
+func TestFileStoreWritesFileAtomically(t *testing.T) { //nolint: paralleltest // sets environment variables
+	secguroConfigDirPath := setUpFileStore(t, "passphrase")
+
+	for _, secret := range []string{"secret1", "secret2"} {
+		err := getFileStore(t).Set("default", secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(secguroConfigDirPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != credentialsFileName {
+		t.Fatalf("expected only %s to be left, got %v", credentialsFileName, entries)
+	}
+
+	fileInfo, err := entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileInfo.Mode().Perm() != 0600 {
+		t.Fatalf("expected permissions 0600, got %v", fileInfo.Mode().Perm())
+	}
+
+	file := readCredentialsFile(t, secguroConfigDirPath)
+	if file.Version != credentialsFileVersion {
+		t.Fatalf("expected version %d, got %d", credentialsFileVersion, file.Version)
+	}
+}
@@ -0,0 +1,45 @@
+package credentials
+
+import (
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+const keyringServiceName = "secguro"
+
+// Uses the Secret Service on Linux, the Keychain on macOS and the Credential Manager on Windows.
+type keyringStore struct{}
+
+func (keyringStore) Name() string {
+	return credentialStoreNameKeyring
+}
+
+func (keyringStore) Get(profileName string) (string, error) {
+	secret, err := keyring.Get(keyringServiceName, profileName)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+
+	return secret, err
+}
+
+func (keyringStore) Set(profileName string, secret string) error {
+	return keyring.Set(keyringServiceName, profileName, secret)
+}
+
+func (keyringStore) Delete(profileName string) error {
+	err := keyring.Delete(keyringServiceName, profileName)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// The keyring is considered unavailable if it cannot even be queried (e.g. no D-Bus session).
+func isKeyringAvailable() bool {
+	_, err := keyring.Get(keyringServiceName, "availability-check")
+
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
@@ -1,20 +1,13 @@
 package dependencies
 
-import "github.com/secguro/secguro-cli/pkg/utils"
+import "path/filepath"
 
 func DownloadBfg() error {
-	filePath := DependenciesDir + "/" + "bfg.jar"
-	url := "https://repo1.maven.org/maven2/com/madgag/bfg/1.14.0/bfg-1.14.0.jar"
-
-	doesAlreadExist, err := utils.DoesFileExist(filePath)
-	if err != nil {
-		return err
-	}
-	if doesAlreadExist {
-		return nil
-	}
+	return installSelected(bfgDependency)
+}
 
-	err = downloadDependency(filePath, url)
+func GetBfgJarPath() (string, error) {
+	installDirPath, err := getSelectedInstallDirPath(bfgDependency)
 
-	return err
+	return filepath.Join(installDirPath, bfgDependency.FileName), err
 }
@@ -0,0 +1,321 @@
+package dependencies
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/secguro/secguro-cli/pkg/logging"
+	"github.com/secguro/secguro-cli/pkg/utils"
+)
+
+// Choices of where the binary of a detector comes from. Any other choice is the path of the binary.
+const (
+	BinaryChoiceAuto    = "auto"    // from PATH if it is recent enough, otherwise managed (default)
+	BinaryChoicePath    = "path"    // from PATH only
+	BinaryChoiceManaged = "managed" // downloaded by secguro (or installed with pipx in the case of Semgrep)
+)
+
+const (
+	binarySourcePath       = "PATH"
+	binarySourceConfigured = "configured"
+	binarySourceManaged    = "managed"
+)
+
+type Binary struct {
+	Path    string
+	Version string
+	Source  string
+}
+
+type binarySpec struct {
+	name            string
+	executableNames []string // looked up on PATH in this order
+	versionArgs     []string
+	minVersion      string                 // older versions lack features that secguro relies on
+	getManaged      func() (Binary, error) // installs the managed binary if necessary
+}
+
+var gitleaksBinarySpec = binarySpec{
+	name:            gitleaksDependency.Name,
+	executableNames: []string{"gitleaks"},
+	versionArgs:     []string{"version"},
+	minVersion:      "8.0.0",
+	getManaged: func() (Binary, error) {
+		return getManagedBinary(gitleaksDependency, getManagedGitleaksExecutablePath)
+	},
+}
+
+var dependencycheckBinarySpec = binarySpec{
+	name:            dependencycheckDependency.Name,
+	executableNames: []string{"dependency-check", "dependency-check.sh"},
+	versionArgs:     []string{"--version"},
+	minVersion:      "9.0.0", // the NVD API is used since version 9
+	getManaged: func() (Binary, error) {
+		return getManagedBinary(dependencycheckDependency, getManagedDependencycheckExecutablePath)
+	},
+}
+
+var semgrepVersionArgs = []string{"--version"}
+
+var semgrepBinarySpec = binarySpec{
+	name:            semgrepName,
+	executableNames: []string{"semgrep"},
+	versionArgs:     semgrepVersionArgs,
+	minVersion:      "1.0.0",
+	getManaged:      getManagedSemgrepBinary,
+}
+
+var binarySpecs = []binarySpec{gitleaksBinarySpec, dependencycheckBinarySpec, semgrepBinarySpec}
+
+var versionRegexInOutput = regexp.MustCompile(`\d+(\.\d+)+`)
+
+var binaryMutex sync.Mutex
+var binaryChoices = make(map[string]string)
+var resolvedBinaries = make(map[string]Binary)
+
+func getBinarySpec(name string) (binarySpec, bool) {
+	for _, spec := range binarySpecs {
+		if spec.name == name {
+			return spec, true
+		}
+	}
+
+	return binarySpec{}, false
+}
+
+// Parses values of the form "detector=choice" (e.g. "gitleaks=path" or "gitleaks=/usr/bin/gitleaks").
+func ParseBinaryChoices(values []string) (map[string]string, error) {
+	choices := make(map[string]string)
+	for _, value := range values {
+		detectorName, choice, found := strings.Cut(value, "=")
+		if !found || choice == "" {
+			return nil, errors.New("invalid detector binary (expected detector=auto|path|managed|<path>): " + value)
+		}
+
+		err := ValidateBinaryChoices(map[string]string{detectorName: choice})
+		if err != nil {
+			return nil, err
+		}
+
+		choices[detectorName] = choice
+	}
+
+	return choices, nil
+}
+
+func ValidateBinaryChoices(choices map[string]string) error {
+	names := make([]string, 0)
+	for _, spec := range binarySpecs {
+		names = append(names, spec.name)
+	}
+
+	for detectorName, choice := range choices {
+		if !slices.Contains(names, detectorName) {
+			return fmt.Errorf("detector binaries can only be chosen for %s, not for %s",
+				strings.Join(names, ", "), detectorName)
+		}
+
+		if choice == "" {
+			return errors.New("empty detector binary choice for " + detectorName)
+		}
+	}
+
+	return nil
+}
+
+// Detectors without choice use BinaryChoiceAuto.
+func SetBinaryChoices(choices map[string]string) {
+	binaryMutex.Lock()
+	defer binaryMutex.Unlock()
+
+	binaryChoices = choices
+	resolvedBinaries = make(map[string]Binary)
+}
+
+func getBinaryChoice(detectorName string) string {
+	binaryMutex.Lock()
+	defer binaryMutex.Unlock()
+
+	choice, ok := binaryChoices[detectorName]
+	if !ok {
+		return BinaryChoiceAuto
+	}
+
+	return choice
+}
+
+// Determines the binary once per process, installing the managed binary if it is to be used.
+func getBinary(spec binarySpec) (Binary, error) {
+	binaryMutex.Lock()
+	binary, isResolved := resolvedBinaries[spec.name]
+	binaryMutex.Unlock()
+	if isResolved {
+		return binary, nil
+	}
+
+	systemBinary, err := findSystemBinary(spec)
+	if err != nil {
+		return Binary{}, err
+	}
+
+	if systemBinary != nil {
+		binary = *systemBinary
+	} else {
+		binary, err = spec.getManaged()
+		if err != nil {
+			return Binary{}, err
+		}
+
+		err = checkMinVersion(spec, binary)
+		if err != nil {
+			return Binary{}, err
+		}
+	}
+
+	logging.Info("Using " + spec.name + " " + binary.Version + " (" + binary.Source + ": " + binary.Path + ")")
+
+	binaryMutex.Lock()
+	resolvedBinaries[spec.name] = binary
+	binaryMutex.Unlock()
+
+	return binary, nil
+}
+
+/**
+ * Returns the binary on PATH or at the configured path, or nil if the managed binary is to be
+ * used. Binaries that are too old are only skipped in favor of the managed binary with
+ * BinaryChoiceAuto; otherwise, they result in an error.
+ */
+func findSystemBinary(spec binarySpec) (*Binary, error) {
+	choice := getBinaryChoice(spec.name)
+
+	switch choice {
+	case BinaryChoiceManaged:
+		return nil, nil //nolint: nilnil
+	case BinaryChoiceAuto, BinaryChoicePath:
+		for _, executableName := range spec.executableNames {
+			path, err := exec.LookPath(executableName)
+			if err != nil {
+				continue
+			}
+
+			binary, err := inspectBinary(path, spec.versionArgs, binarySourcePath)
+			if err == nil {
+				err = checkMinVersion(spec, binary)
+			}
+			if err == nil {
+				return &binary, nil
+			}
+			if choice == BinaryChoicePath {
+				return nil, err
+			}
+
+			logging.Info("Not using " + path + " (" + err.Error() + ")")
+		}
+
+		if choice == BinaryChoicePath {
+			return nil, errors.New(spec.name + " not found on PATH")
+		}
+
+		return nil, nil //nolint: nilnil
+	default:
+		// Detectors are run in the directory to scan.
+		path, err := filepath.Abs(choice)
+		if err != nil {
+			return nil, err
+		}
+
+		binary, err := inspectBinary(path, spec.versionArgs, binarySourceConfigured)
+		if err != nil {
+			return nil, err
+		}
+
+		return &binary, checkMinVersion(spec, binary)
+	}
+}
+
+// Determines the version by running the binary.
+func inspectBinary(path string, versionArgs []string, source string) (Binary, error) {
+	out, err := utils.CommandOutput(exec.Command(path, versionArgs...))
+	if err != nil {
+		return Binary{}, fmt.Errorf("failed to run %s: %w", path, err)
+	}
+
+	version := versionRegexInOutput.FindString(string(out))
+	if version == "" {
+		return Binary{}, errors.New("failed to determine the version of " + path)
+	}
+
+	return Binary{Path: path, Version: version, Source: source}, nil
+}
+
+func checkMinVersion(spec binarySpec, binary Binary) error {
+	if compareVersions(binary.Version, spec.minVersion) < 0 {
+		return fmt.Errorf("%s %s is too old; at least version %s is required",
+			spec.name, binary.Version, spec.minVersion)
+	}
+
+	return nil
+}
+
+// Compares the numeric components of dot-separated versions; anything after them is ignored.
+func compareVersions(a string, b string) int {
+	aComponents := getVersionComponents(a)
+	bComponents := getVersionComponents(b)
+
+	for i := range max(len(aComponents), len(bComponents)) {
+		aComponent, bComponent := 0, 0
+		if i < len(aComponents) {
+			aComponent = aComponents[i]
+		}
+		if i < len(bComponents) {
+			bComponent = bComponents[i]
+		}
+
+		if aComponent != bComponent {
+			return aComponent - bComponent
+		}
+	}
+
+	return 0
+}
+
+func getVersionComponents(version string) []int {
+	components := make([]int, 0)
+	for _, componentString := range strings.Split(versionRegexInOutput.FindString(version), ".") {
+		component, err := strconv.Atoi(componentString)
+		if err != nil {
+			break
+		}
+
+		components = append(components, component)
+	}
+
+	return components
+}
+
+func getManagedBinary(dependency Dependency, getPath func() (string, error)) (Binary, error) {
+	selectedDependency, err := getSelectedDependency(dependency)
+	if err != nil {
+		return Binary{}, err
+	}
+
+	err = install(selectedDependency)
+	if err != nil {
+		return Binary{}, err
+	}
+
+	path, err := getPath()
+	if err != nil {
+		return Binary{}, err
+	}
+
+	return Binary{Path: path, Version: selectedDependency.Version, Source: binarySourceManaged}, nil
+}
@@ -0,0 +1,573 @@
+package dependencies
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/codeclysm/extract/v3"
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/logging"
+	"github.com/secguro/secguro-cli/pkg/utils"
+)
+
+/**
+ * A bundle is a tar.gz file with everything that the detectors need to run without network
+ * access: the installation directories of the dependencies (as in the dependencies directory),
+ * the vulnerability data of dependency-check, Python wheels of Semgrep and Semgrep's default
+ * rules. bundle.json is the first entry so that it can be read without extracting the bundle.
+ */
+const bundleInfoFileName = "bundle.json"
+
+const (
+	dependencycheckDataDirName = "dependencycheck-data"
+	semgrepWheelsDirName       = "semgrep-wheels"
+	semgrepRulesDirName        = "semgrep-rules"
+)
+
+const semgrepRulesUrl = "https://semgrep.dev/c/p/default"
+const semgrepRulesFileName = "default.yml"
+
+const offlineHint = "in offline mode, provide a bundle created by `secguro deps bundle` with --bundle"
+
+type bundleInfo struct {
+	Platform       string // "<GOOS>/<GOARCH>"
+	SecguroVersion string
+	CreatedAt      string
+	Versions       map[string]string // of the bundled dependencies by name
+}
+
+var bundleImportOnce sync.Once
+var bundleImportErr error
+
+func CommandBundle(outputPath string) error {
+	if config.Offline {
+		return errors.New("creating a bundle requires network access and cannot be done in offline mode")
+	}
+
+	nvdApiKey := os.Getenv(config.NvdApiKeyEnvVarName)
+	if nvdApiKey == "" {
+		return errors.New("creating a bundle requires an NVD API key in " + config.NvdApiKeyEnvVarName +
+			" to download the vulnerability data of dependency-check")
+	}
+
+	if outputPath == "" {
+		outputPath = "secguro-bundle-" + runtime.GOOS + "-" + runtime.GOARCH + ".tar.gz"
+	}
+
+	bundledDirPaths := make(map[string]string) // by path in the bundle
+	bundledDirNames := make([]string, 0)
+	bundledVersions := make(map[string]string)
+
+	for _, manifestDependency := range manifest {
+		if _, ok := manifestDependency.getArtifact(); !ok {
+			continue
+		}
+
+		dependency, err := getSelectedDependency(manifestDependency)
+		if err != nil {
+			return err
+		}
+
+		logging.Progress("Installing " + dependency.Name + " " + dependency.Version)
+		err = install(dependency)
+		if err != nil {
+			logging.ProgressDone("failed")
+			return err
+		}
+		logging.ProgressDone("done")
+
+		installDirPath, err := getInstallDirPath(dependency)
+		if err != nil {
+			return err
+		}
+
+		bundledDirNames = append(bundledDirNames, filepath.Base(installDirPath))
+		bundledDirPaths[filepath.Base(installDirPath)] = installDirPath
+		bundledVersions[dependency.Name] = dependency.Version
+	}
+
+	stagingDirPath, err := os.MkdirTemp("", "secguro-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDirPath)
+
+	err = stageBundleData(stagingDirPath, nvdApiKey)
+	if err != nil {
+		return err
+	}
+
+	for _, dirName := range []string{dependencycheckDataDirName, semgrepWheelsDirName, semgrepRulesDirName} {
+		bundledDirNames = append(bundledDirNames, dirName)
+		bundledDirPaths[dirName] = filepath.Join(stagingDirPath, dirName)
+	}
+
+	info := bundleInfo{
+		Platform:       runtime.GOOS + "/" + runtime.GOARCH,
+		SecguroVersion: config.Version,
+		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+		Versions:       bundledVersions,
+	}
+
+	logging.Progress("Writing bundle")
+	err = writeBundle(outputPath, info, bundledDirNames, bundledDirPaths)
+	if err != nil {
+		logging.ProgressDone("failed")
+		return err
+	}
+	logging.ProgressDone("done")
+
+	logging.Info("Bundle written to: " + outputPath)
+
+	return nil
+}
+
+// Downloads the data that the detectors would otherwise fetch at scan time.
+func stageBundleData(stagingDirPath string, nvdApiKey string) error {
+	// The data has to match the bundled version rather than one installed on this machine.
+	dependencycheckExecutablePath, err := getManagedDependencycheckExecutablePath()
+	if err != nil {
+		return err
+	}
+
+	logging.Progress("Downloading vulnerability data of dependency-check (this may take a while)")
+	// secguro-ignore-next-line
+	cmd := exec.Command(dependencycheckExecutablePath, "--updateonly",
+		"--data", filepath.Join(stagingDirPath, dependencycheckDataDirName),
+		"--nvdApiKey", nvdApiKey)
+	_, err = utils.CommandOutput(cmd)
+	if err != nil {
+		logging.ProgressDone("failed")
+		return fmt.Errorf("failed to download vulnerability data of dependency-check: %w",
+			utils.CheckExitCode("dependency-check", err))
+	}
+	logging.ProgressDone("done")
+
+	logging.Progress("Downloading Semgrep")
+	cmd = exec.Command("python3", "-m", "pip", "download", "semgrep",
+		"--dest", filepath.Join(stagingDirPath, semgrepWheelsDirName))
+	_, err = utils.CommandOutput(cmd)
+	if err != nil {
+		logging.ProgressDone("failed")
+		return errors.New("failed to download Semgrep. Make sure that python3 and pip are installed.")
+	}
+	logging.ProgressDone("done")
+
+	logging.Progress("Downloading Semgrep rules")
+	err = downloadSemgrepRules(filepath.Join(stagingDirPath, semgrepRulesDirName))
+	if err != nil {
+		logging.ProgressDone("failed")
+		return fmt.Errorf("failed to download Semgrep rules: %w", err)
+	}
+	logging.ProgressDone("done")
+
+	return nil
+}
+
+func downloadSemgrepRules(semgrepRulesDirPath string) error {
+	err := os.MkdirAll(semgrepRulesDirPath, directoryPermissions)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(semgrepRulesDirPath, semgrepRulesFileName))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = downloadTo(file, semgrepRulesUrl)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// Writes into a temporary file that is renamed once complete.
+func writeBundle(outputPath string, info bundleInfo, dirNames []string, dirPathsByName map[string]string) error {
+	file, err := os.CreateTemp(filepath.Dir(outputPath), ".secguro-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err = writeBundleInfo(tarWriter, info)
+	if err != nil {
+		return err
+	}
+
+	for _, dirName := range dirNames {
+		err = addDirToTar(tarWriter, dirPathsByName[dirName], dirName)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	err = gzipWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), outputPath)
+}
+
+func writeBundleInfo(tarWriter *tar.Writer, info bundleInfo) error {
+	infoJson, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	const filePermissions = 0644
+	err = tarWriter.WriteHeader(&tar.Header{ //nolint: exhaustruct
+		Typeflag: tar.TypeReg,
+		Name:     bundleInfoFileName,
+		Mode:     filePermissions,
+		Size:     int64(len(infoJson)),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(infoJson)
+
+	return err
+}
+
+func addDirToTar(tarWriter *tar.Writer, dirPath string, nameInTar string) error {
+	return filepath.WalkDir(dirPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativeFilePath, err := filepath.Rel(dirPath, filePath)
+		if err != nil {
+			return err
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		linkTarget := ""
+		if fileInfo.Mode()&fs.ModeSymlink != 0 {
+			linkTarget, err = os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(fileInfo, linkTarget)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(nameInTar, relativeFilePath))
+		if entry.IsDir() {
+			header.Name += "/"
+		}
+
+		err = tarWriter.WriteHeader(header)
+		if err != nil || !fileInfo.Mode().IsRegular() {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tarWriter, file)
+
+		return err
+	})
+}
+
+// Imports the bundle given with --bundle (if any) once per process.
+func ensureBundleImported() error {
+	bundleImportOnce.Do(func() {
+		if config.BundlePath != "" {
+			bundleImportErr = importBundle(config.BundlePath)
+		}
+	})
+
+	return bundleImportErr
+}
+
+/**
+ * Installs the dependencies of the bundle that are not installed yet and replaces the data of
+ * the detectors with that of the bundle. Nothing is done if the bundle has been imported before.
+ */
+func importBundle(bundlePath string) error {
+	info, err := readBundleInfo(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle %s: %w", bundlePath, err)
+	}
+
+	if info.Versions == nil {
+		return fmt.Errorf("bundle %s does not list the versions of its dependencies", bundlePath)
+	}
+
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	if info.Platform != platform {
+		return fmt.Errorf("bundle %s was created for %s and cannot be used on %s", bundlePath, info.Platform, platform)
+	}
+
+	dirPath, err := GetDirPath()
+	if err != nil {
+		return err
+	}
+
+	importedInfo, err := readImportedBundleInfo(dirPath)
+	if err != nil {
+		return err
+	}
+	if importedInfo.Platform == info.Platform && importedInfo.CreatedAt == info.CreatedAt {
+		return nil
+	}
+
+	logging.Info("Importing bundle " + bundlePath + " (created " + info.CreatedAt + ")")
+	err = extractAndImportBundle(bundlePath, info, dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to import bundle %s: %w", bundlePath, err)
+	}
+
+	return nil
+}
+
+func extractAndImportBundle(bundlePath string, info bundleInfo, dirPath string) error {
+	err := os.MkdirAll(dirPath, directoryPermissions)
+	if err != nil {
+		return err
+	}
+
+	tmpDirPath, err := os.MkdirTemp(dirPath, ".bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDirPath)
+
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = extract.Gz(context.Background(), file, tmpDirPath, nil)
+	if err != nil {
+		return err
+	}
+
+	selectedVersions, err := readSelectedVersions()
+	if err != nil {
+		return err
+	}
+
+	for _, manifestDependency := range manifest {
+		// Dependencies that are not available for the platform are not bundled.
+		version, isBundled := info.Versions[manifestDependency.Name]
+		if !isBundled {
+			continue
+		}
+
+		dependency, err := manifestDependency.withVersion(version, "")
+		if err != nil {
+			return err
+		}
+
+		err = importBundledDependency(dependency, bundlePath, tmpDirPath)
+		if err != nil {
+			return err
+		}
+
+		// Use the bundled versions, as they are the only ones available offline.
+		if version == manifestDependency.Version {
+			delete(selectedVersions, dependency.Name)
+		} else {
+			selectedVersions[dependency.Name] = version
+		}
+	}
+
+	err = writeSelectedVersions(selectedVersions)
+	if err != nil {
+		return err
+	}
+
+	for _, dataDirName := range []string{dependencycheckDataDirName, semgrepWheelsDirName, semgrepRulesDirName} {
+		err = replaceDir(filepath.Join(tmpDirPath, dataDirName), filepath.Join(dirPath, dataDirName))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Recorded last so that an interrupted import is repeated.
+	return os.Rename(filepath.Join(tmpDirPath, bundleInfoFileName), filepath.Join(dirPath, bundleInfoFileName))
+}
+
+/**
+ * Installed dependencies are kept; their integrity is checked by `secguro deps verify`.
+ */
+func importBundledDependency(dependency Dependency, bundlePath string, bundleDirPath string) error {
+	installed, err := isInstalled(dependency)
+	if err != nil || installed {
+		return err
+	}
+
+	bundledInstallDirPath := filepath.Join(bundleDirPath, dependency.Name+"-"+dependency.Version)
+	isBundled, err := utils.DoesFileExist(filepath.Join(bundledInstallDirPath, installationRecordFileName))
+	if err != nil {
+		return err
+	}
+	if !isBundled {
+		return fmt.Errorf("%s %s is missing from the bundle", dependency.Name, dependency.Version)
+	}
+
+	problems, err := getInstallationProblems(dependency, bundledInstallDirPath)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s in the bundle is corrupted (%s)", dependency.Name, problems[0])
+	}
+
+	// Shown by `secguro deps list`.
+	record, err := readInstallationRecord(bundledInstallDirPath)
+	if err != nil {
+		return err
+	}
+	record.Bundle, err = filepath.Abs(bundlePath)
+	if err != nil {
+		return err
+	}
+	err = writeInstallationRecord(bundledInstallDirPath, record)
+	if err != nil {
+		return err
+	}
+
+	return moveIntoPlace(dependency, bundledInstallDirPath)
+}
+
+func replaceDir(sourceDirPath string, targetDirPath string) error {
+	exists, err := utils.DoesFileExist(sourceDirPath)
+	if err != nil || !exists {
+		return err
+	}
+
+	err = os.RemoveAll(targetDirPath)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(sourceDirPath, targetDirPath)
+}
+
+// Only reads the first entry of the bundle.
+func readBundleInfo(bundlePath string) (bundleInfo, error) {
+	var info bundleInfo
+
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return info, err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return info, err
+	}
+
+	tarReader := tar.NewReader(gzipReader)
+	header, err := tarReader.Next()
+	if err != nil {
+		return info, err
+	}
+	if header.Name != bundleInfoFileName {
+		return info, errors.New("not a secguro bundle")
+	}
+
+	err = json.NewDecoder(tarReader).Decode(&info)
+
+	return info, err
+}
+
+// Returns the zero value if no bundle has been imported.
+func readImportedBundleInfo(dirPath string) (bundleInfo, error) {
+	var info bundleInfo
+
+	infoJson, err := os.ReadFile(filepath.Join(dirPath, bundleInfoFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return info, nil
+	}
+	if err != nil {
+		return info, err
+	}
+
+	err = json.Unmarshal(infoJson, &info)
+
+	return info, err
+}
+
+func getDataDirPath(dataDirName string, description string) (string, error) {
+	err := ensureBundleImported()
+	if err != nil {
+		return "", err
+	}
+
+	dirPath, err := GetDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	dataDirPath := filepath.Join(dirPath, dataDirName)
+	exists, err := utils.DoesFileExist(dataDirPath)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", errors.New(description + " is not available; " + offlineHint)
+	}
+
+	return dataDirPath, nil
+}
+
+func GetDependencycheckDataDirPath() (string, error) {
+	return getDataDirPath(dependencycheckDataDirName, "the vulnerability data of dependency-check")
+}
+
+func GetSemgrepRulesFilePath() (string, error) {
+	semgrepRulesDirPath, err := getDataDirPath(semgrepRulesDirName, "the Semgrep rules")
+
+	return filepath.Join(semgrepRulesDirPath, semgrepRulesFileName), err
+}
+
+func getSemgrepWheelsDirPath() (string, error) {
+	return getDataDirPath(semgrepWheelsDirName, "Semgrep")
+}
@@ -1,25 +1,22 @@
 package dependencies
 
-import "github.com/secguro/secguro-cli/pkg/utils"
+import "path/filepath"
 
-func downloadAndExtractDependencycheck() error {
-	filePath := DependenciesDir + "/" + "dependencycheck.zip"
-	url := "https://github.com/jeremylong/DependencyCheck/releases/download/v9.0.9/dependency-check-9.0.9-release.zip"
+// Installs dependency-check unless a suitable binary is available already.
+func InstallDependencycheck() error {
+	_, err := getBinary(dependencycheckBinarySpec)
 
-	doesAlreadExist, err := utils.DoesFileExist(filePath)
-	if err != nil {
-		return err
-	}
-	if doesAlreadExist {
-		return nil
-	}
+	return err
+}
 
-	err = downloadDependency(filePath, url)
-	if err != nil {
-		return err
-	}
+func GetDependencycheckExecutablePath() (string, error) {
+	binary, err := getBinary(dependencycheckBinarySpec)
 
-	err = extractZipDependency("dependencycheck")
+	return binary.Path, err
+}
 
-	return err
+func getManagedDependencycheckExecutablePath() (string, error) {
+	installDirPath, err := getSelectedInstallDirPath(dependencycheckDependency)
+
+	return filepath.Join(installDirPath, "dependency-check", "bin", "dependency-check.sh"), err
 }
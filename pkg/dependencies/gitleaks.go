@@ -1,39 +1,22 @@
 package dependencies
 
-import (
-	"errors"
-	"runtime"
+import "path/filepath"
 
-	"github.com/secguro/secguro-cli/pkg/utils"
-)
+// Installs gitleaks unless a suitable binary is available already.
+func InstallGitleaks() error {
+	_, err := getBinary(gitleaksBinarySpec)
 
-func downloadAndExtractGitleaks() error {
-	var url string
-	switch runtime.GOOS {
-	case "linux":
-		url = "https://github.com/gitleaks/gitleaks/releases/download/v8.18.3/gitleaks_8.18.3_linux_x64.tar.gz"
-	case "darwin":
-		url = "https://github.com/gitleaks/gitleaks/releases/download/v8.18.3/gitleaks_8.18.3_darwin_arm64.tar.gz"
-	default:
-		return errors.New("Unsupported platform")
-	}
-
-	filePath := DependenciesDir + "/" + "gitleaks.tar.gz"
+	return err
+}
 
-	doesAlreadExist, err := utils.DoesFileExist(filePath)
-	if err != nil {
-		return err
-	}
-	if doesAlreadExist {
-		return nil
-	}
+func GetGitleaksExecutablePath() (string, error) {
+	binary, err := getBinary(gitleaksBinarySpec)
 
-	err = downloadDependency(filePath, url)
-	if err != nil {
-		return err
-	}
+	return binary.Path, err
+}
 
-	err = extractGzDependency("gitleaks")
+func getManagedGitleaksExecutablePath() (string, error) {
+	installDirPath, err := getSelectedInstallDirPath(gitleaksDependency)
 
-	return err
+	return filepath.Join(installDirPath, "gitleaks"), err
 }
@@ -0,0 +1,134 @@
+package dependencies
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+/**
+ * Lists the version of each dependency that is used and where it has been installed from.
+ * For detectors, binaries on PATH or at configured paths take the place of the managed ones.
+ */
+func CommandList() error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint: mnd
+	fmt.Fprintln(writer, "NAME\tVERSION\tSTATUS\tSOURCE")
+
+	unusedVersions := make([]string, 0)
+
+	for _, manifestDependency := range manifest {
+		if _, ok := manifestDependency.getArtifact(); !ok {
+			continue
+		}
+
+		dependency, err := getSelectedDependency(manifestDependency)
+		if err != nil {
+			return err
+		}
+
+		row, err := getSystemBinaryRow(dependency.Name)
+		if err != nil {
+			return err
+		}
+
+		if row == "" {
+			status, source, err := getStatusAndSource(dependency)
+			if err != nil {
+				return err
+			}
+
+			row = dependency.Name + "\t" + dependency.Version + "\t" + status + "\t" + source
+		}
+
+		fmt.Fprintln(writer, strings.TrimSuffix(row, "\t"))
+
+		installedVersions, err := getInstalledVersions(dependency.Name)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range installedVersions {
+			if version != dependency.Version {
+				unusedVersions = append(unusedVersions, dependency.Name+" "+version)
+			}
+		}
+	}
+
+	semgrepRow, err := getSemgrepRow()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(writer, semgrepRow)
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	if len(unusedVersions) > 0 {
+		fmt.Println("\nUnused versions (remove with secguro deps clean): " + strings.Join(unusedVersions, ", "))
+	}
+
+	return nil
+}
+
+func getStatusAndSource(dependency Dependency) (string, string, error) {
+	installed, err := isInstalled(dependency)
+	if err != nil || !installed {
+		return "not installed", "", err
+	}
+
+	installDirPath, err := getInstallDirPath(dependency)
+	if err != nil {
+		return "", "", err
+	}
+
+	record, err := readInstallationRecord(installDirPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	if record.Bundle != "" {
+		return "installed", "bundle " + record.Bundle, nil
+	}
+
+	return "installed", record.Url, nil
+}
+
+// Returns an empty row if the detector uses the managed binary (or is not a detector).
+func getSystemBinaryRow(name string) (string, error) {
+	spec, ok := getBinarySpec(name)
+	if !ok {
+		return "", nil
+	}
+
+	binary, err := findSystemBinary(spec)
+	if err != nil {
+		return name + "\t-\tunusable\t" + err.Error(), nil
+	}
+	if binary == nil {
+		return "", nil
+	}
+
+	return name + "\t" + binary.Version + "\tinstalled\t" + binary.Source + " " + binary.Path, nil
+}
+
+func getSemgrepRow() (string, error) {
+	row, err := getSystemBinaryRow(semgrepName)
+	if err != nil || row != "" {
+		return row, err
+	}
+
+	semgrepPath, err := getPipxSemgrepPath()
+	if err != nil {
+		return semgrepName + "\t-\tnot installed", nil //nolint: nilerr
+	}
+
+	binary, err := inspectBinary(semgrepPath, semgrepVersionArgs, binarySourceManaged)
+	if err != nil {
+		return semgrepName + "\t-\tnot installed", nil //nolint: nilerr
+	}
+
+	return semgrepName + "\t" + binary.Version + "\tinstalled\tpipx " + binary.Path, nil
+}
@@ -0,0 +1,349 @@
+package dependencies
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/logging"
+)
+
+// Prefixes of temporary files and directories that interrupted operations may leave behind.
+var tmpFileNamePrefixes = []string{".download-", ".install-", ".bundle-"}
+
+/**
+ * Installs the given dependencies (all if none are given). versionArgs choose other versions
+ * than those of the manifest (e.g. "gitleaks=8.19.0"); the choice is kept for later scans.
+ * As checksums are only pinned for the manifest, checksumArgs give the SHA-256 checksums of
+ * the artifacts of other versions (e.g. "gitleaks=<sha256>").
+ */
+func CommandInstall(names []string, versionArgs []string, checksumArgs []string) error {
+	versions, err := parseNameValueArgs(versionArgs, "version")
+	if err != nil {
+		return err
+	}
+
+	checksums, err := parseNameValueArgs(checksumArgs, "checksum")
+	if err != nil {
+		return err
+	}
+
+	names, err = getNamesOrAll(names)
+	if err != nil {
+		return err
+	}
+
+	for name := range versions {
+		if !slices.Contains(names, name) {
+			return errors.New("a version is given for " + name + ", which is not to be installed")
+		}
+	}
+
+	for name := range checksums {
+		if versions[name] == "" || name == semgrepName {
+			return errors.New("a checksum can only be given for a dependency that is installed with --version: " + name)
+		}
+	}
+
+	for _, name := range names {
+		if name == semgrepName {
+			err = runWithProgress("Installing "+semgrepName, func() error {
+				return installSemgrepVersion(versions[name])
+			})
+		} else {
+			err = installDependencyOfManifest(name, versions[name], checksums[name])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// An empty version means the version chosen before or, without such choice, that of the manifest.
+func installDependencyOfManifest(name string, version string, sha256Sum string) error {
+	manifestDependency, _ := getDependencyByName(name)
+
+	dependency, err := getSelectedDependency(manifestDependency)
+	if version != "" {
+		dependency, err = manifestDependency.withVersion(version, sha256Sum)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = runWithProgress("Installing "+dependency.Name+" "+dependency.Version, func() error {
+		return install(dependency)
+	})
+	if err != nil {
+		return err
+	}
+
+	return selectVersion(manifestDependency, dependency.Version)
+}
+
+/**
+ * Switches the given dependencies (all if none are given) to the versions of the manifest and
+ * removes their other versions. Semgrep, which is not pinned, is updated to its latest version.
+ */
+func CommandUpdate(names []string) error {
+	if config.Offline {
+		return errors.New("updating dependencies requires network access and cannot be done in offline mode")
+	}
+
+	names, err := getNamesOrAll(names)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if name == semgrepName {
+			err = runWithProgress("Updating "+semgrepName, updateSemgrep)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		dependency, _ := getDependencyByName(name)
+		err = runWithProgress("Updating "+dependency.Name+" to "+dependency.Version, func() error {
+			return install(dependency)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = selectVersion(dependency, dependency.Version)
+		if err != nil {
+			return err
+		}
+
+		err = removeVersions(dependency.Name, func(version string) bool {
+			return version != dependency.Version
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+ * Removes the versions of dependencies that are not used and leftovers of interrupted
+ * downloads. With all, everything that secguro has stored in the dependencies directory
+ * is removed. Semgrep, which is installed with pipx, is kept.
+ */
+func CommandClean(all bool) error {
+	for _, manifestDependency := range manifest {
+		dependency, err := getSelectedDependency(manifestDependency)
+		if err != nil {
+			return err
+		}
+
+		err = removeVersions(dependency.Name, func(version string) bool {
+			return all || version != dependency.Version
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	dirPath, err := GetDirPath()
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !isRemovedOnClean(entry.Name(), all) {
+			continue
+		}
+
+		err = os.RemoveAll(filepath.Join(dirPath, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	if all {
+		// Only succeeds if the directory contains nothing that secguro does not know about.
+		_ = os.Remove(dirPath)
+	}
+
+	return nil
+}
+
+func isRemovedOnClean(fileName string, all bool) bool {
+	for _, tmpFileNamePrefix := range tmpFileNamePrefixes {
+		if strings.HasPrefix(fileName, tmpFileNamePrefix) {
+			return true
+		}
+	}
+
+	return all && slices.Contains([]string{dependencycheckDataDirName, semgrepWheelsDirName,
+		semgrepRulesDirName, bundleInfoFileName, selectedVersionsFileName}, fileName)
+}
+
+// Removes the installed versions of the dependency for which shouldRemove returns true.
+func removeVersions(name string, shouldRemove func(version string) bool) error {
+	installedVersions, err := getInstalledVersions(name)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range installedVersions {
+		if !shouldRemove(version) {
+			continue
+		}
+
+		dependency, _ := getDependencyByName(name)
+		dependency, err = dependency.withVersion(version, "")
+		if err != nil {
+			return err
+		}
+
+		installDirPath, err := getInstallDirPath(dependency)
+		if err != nil {
+			return err
+		}
+
+		err = os.RemoveAll(installDirPath)
+		if err != nil {
+			return err
+		}
+
+		logging.Info("Removed " + name + " " + version)
+	}
+
+	return nil
+}
+
+// Versions are determined from the installation records because names of other directories
+// may start with the name of a dependency as well (e.g. dependencycheck-data).
+func getInstalledVersions(name string) ([]string, error) {
+	dirPath, err := GetDirPath()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	installedVersions := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), name+"-") {
+			continue
+		}
+
+		record, err := readInstallationRecord(filepath.Join(dirPath, entry.Name()))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if record.Name == name && entry.Name() == name+"-"+record.Version {
+			installedVersions = append(installedVersions, record.Version)
+		}
+	}
+
+	return installedVersions, nil
+}
+
+func selectVersion(manifestDependency Dependency, version string) error {
+	selectedVersions, err := readSelectedVersions()
+	if err != nil {
+		return err
+	}
+
+	if version == manifestDependency.Version {
+		delete(selectedVersions, manifestDependency.Name)
+	} else {
+		selectedVersions[manifestDependency.Name] = version
+	}
+
+	return writeSelectedVersions(selectedVersions)
+}
+
+// Parses values of the form "name=value" (e.g. "gitleaks=8.19.0"), where value is described by valueName.
+func parseNameValueArgs(args []string, valueName string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, arg := range args {
+		name, value, found := strings.Cut(arg, "=")
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid %s (expected name=%s): %s", valueName, valueName, arg)
+		}
+
+		err := validateNames([]string{name})
+		if err != nil {
+			return nil, err
+		}
+
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// Names of the dependencies that are available on this platform, including Semgrep.
+func getNames() []string {
+	names := make([]string, 0)
+	for _, dependency := range manifest {
+		if _, ok := dependency.getArtifact(); ok {
+			names = append(names, dependency.Name)
+		}
+	}
+
+	return append(names, semgrepName)
+}
+
+func getNamesOrAll(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return getNames(), nil
+	}
+
+	return names, validateNames(names)
+}
+
+func validateNames(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(getNames(), name) {
+			return fmt.Errorf("unknown dependency: %s (available: %s)", name, strings.Join(getNames(), ", "))
+		}
+	}
+
+	return nil
+}
+
+func runWithProgress(message string, f func() error) error {
+	logging.Progress(message)
+	err := f()
+	if err != nil {
+		logging.ProgressDone("failed")
+		return err
+	}
+	logging.ProgressDone("done")
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package dependencies
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+const (
+	archiveTypeTarGz = "tar.gz"
+	archiveTypeZip   = "zip"
+	archiveTypeNone  = "" // the downloaded file is used as is
+)
+
+// Key of artifacts that work on all platforms (e.g. jar files).
+const anyPlatform = "any"
+
+type Dependency struct {
+	Name        string
+	Version     string
+	ArchiveType string
+	FileName    string // name of the downloaded file in the installation directory if it is not an archive
+	// Keyed by platform ("<GOOS>/<GOARCH>") or anyPlatform.
+	Artifacts map[string]Artifact
+}
+
+/**
+ * Downloads are verified against Sha256 if given, otherwise against the checksum listed for
+ * the file in ChecksumsUrl (in the format of sha256sum). Artifacts without either are not installed.
+ */
+type Artifact struct {
+	Url          string
+	Sha256       string
+	ChecksumsUrl string
+}
+
+const gitleaksChecksumsUrl = "https://github.com/gitleaks/gitleaks/releases/download/v8.18.3/gitleaks_8.18.3_checksums.txt"
+
+var gitleaksDependency = Dependency{
+	Name:        "gitleaks",
+	Version:     "8.18.3",
+	ArchiveType: archiveTypeTarGz,
+	FileName:    "",
+	Artifacts: map[string]Artifact{
+		"linux/amd64": {
+			Url:          "https://github.com/gitleaks/gitleaks/releases/download/v8.18.3/gitleaks_8.18.3_linux_x64.tar.gz",
+			Sha256:       "",
+			ChecksumsUrl: gitleaksChecksumsUrl,
+		},
+		"linux/arm64": {
+			Url:          "https://github.com/gitleaks/gitleaks/releases/download/v8.18.3/gitleaks_8.18.3_linux_arm64.tar.gz",
+			Sha256:       "",
+			ChecksumsUrl: gitleaksChecksumsUrl,
+		},
+		"darwin/amd64": {
+			Url:          "https://github.com/gitleaks/gitleaks/releases/download/v8.18.3/gitleaks_8.18.3_darwin_x64.tar.gz",
+			Sha256:       "",
+			ChecksumsUrl: gitleaksChecksumsUrl,
+		},
+		"darwin/arm64": {
+			Url:          "https://github.com/gitleaks/gitleaks/releases/download/v8.18.3/gitleaks_8.18.3_darwin_arm64.tar.gz",
+			Sha256:       "",
+			ChecksumsUrl: gitleaksChecksumsUrl,
+		},
+	},
+}
+
+var dependencycheckDependency = Dependency{
+	Name:        "dependencycheck",
+	Version:     "9.0.9",
+	ArchiveType: archiveTypeZip,
+	FileName:    "",
+	Artifacts: map[string]Artifact{
+		anyPlatform: {
+			Url:          "https://github.com/jeremylong/DependencyCheck/releases/download/v9.0.9/dependency-check-9.0.9-release.zip",
+			Sha256:       "",
+			ChecksumsUrl: "",
+		},
+	},
+}
+
+var bfgDependency = Dependency{
+	Name:        "bfg",
+	Version:     "1.14.0",
+	ArchiveType: archiveTypeNone,
+	FileName:    "bfg.jar",
+	Artifacts: map[string]Artifact{
+		anyPlatform: {
+			Url:          "https://repo1.maven.org/maven2/com/madgag/bfg/1.14.0/bfg-1.14.0.jar",
+			Sha256:       "",
+			ChecksumsUrl: "",
+		},
+	},
+}
+
+var manifest = []Dependency{gitleaksDependency, dependencycheckDependency, bfgDependency}
+
+// Returns false if the dependency is not available for the current platform.
+func (dependency Dependency) getArtifact() (Artifact, bool) {
+	artifact, ok := dependency.Artifacts[runtime.GOOS+"/"+runtime.GOARCH]
+	if !ok {
+		artifact, ok = dependency.Artifacts[anyPlatform]
+	}
+
+	return artifact, ok
+}
+
+// Versions are used in directory names and URLs.
+var versionRegex = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z.+-]*$`)
+
+var sha256Regex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+/**
+ * Returns the dependency in a different version, assuming that its URLs only differ in the
+ * version. Pinned checksums only apply to the version of the manifest; sha256Sum is the
+ * checksum of the artifact for the current platform in other versions. Without it or
+ * published checksums, the dependency can be located but not installed.
+ */
+func (dependency Dependency) withVersion(version string, sha256Sum string) (Dependency, error) {
+	if !versionRegex.MatchString(version) {
+		return Dependency{}, errors.New("invalid version of " + dependency.Name + ": " + version)
+	}
+
+	if sha256Sum != "" && !sha256Regex.MatchString(sha256Sum) {
+		return Dependency{}, errors.New("invalid SHA-256 checksum of " + dependency.Name + ": " + sha256Sum)
+	}
+
+	currentArtifact, _ := dependency.getArtifact()
+	artifacts := make(map[string]Artifact, len(dependency.Artifacts))
+	for platform, artifact := range dependency.Artifacts {
+		versionArtifact := Artifact{
+			Url:          strings.ReplaceAll(artifact.Url, dependency.Version, version),
+			Sha256:       "",
+			ChecksumsUrl: strings.ReplaceAll(artifact.ChecksumsUrl, dependency.Version, version),
+		}
+		if version == dependency.Version {
+			versionArtifact.Sha256 = artifact.Sha256
+		}
+
+		if artifact == currentArtifact && sha256Sum != "" {
+			if versionArtifact.Sha256 != "" && versionArtifact.Sha256 != sha256Sum {
+				return Dependency{}, fmt.Errorf("the checksum of %s %s is pinned to %s",
+					dependency.Name, version, versionArtifact.Sha256)
+			}
+
+			versionArtifact.Sha256 = sha256Sum
+		}
+
+		artifacts[platform] = versionArtifact
+	}
+
+	dependency.Version = version
+	dependency.Artifacts = artifacts
+
+	return dependency, nil
+}
+
+func getDependencyByName(name string) (Dependency, bool) {
+	for _, dependency := range manifest {
+		if dependency.Name == name {
+			return dependency, true
+		}
+	}
+
+	return Dependency{}, false
+}
@@ -2,15 +2,122 @@ package dependencies
 
 import (
 	"errors"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/utils"
 )
 
-func installSemgrep() error {
+// Semgrep is installed with pipx instead of being downloaded like the dependencies of the manifest.
+const semgrepName = "semgrep"
+
+// Installs Semgrep unless a suitable binary is available already.
+func InstallSemgrep() error {
+	_, err := getBinary(semgrepBinarySpec)
+
+	return err
+}
+
+func GetSemgrepExecutablePath() (string, error) {
+	binary, err := getBinary(semgrepBinarySpec)
+
+	return binary.Path, err
+}
+
+func getManagedSemgrepBinary() (Binary, error) {
+	err := installSemgrepWithPipx()
+	if err != nil {
+		return Binary{}, err
+	}
+
+	semgrepPath, err := getPipxSemgrepPath()
+	if err != nil {
+		return Binary{}, err
+	}
+
+	return inspectBinary(semgrepPath, semgrepVersionArgs, binarySourceManaged)
+}
+
+// Where pipx installs the semgrep executable (usually ~/.local/bin/semgrep).
+func getPipxSemgrepPath() (string, error) {
+	cmd := exec.Command("python3", "-m", "pipx", "environment", "--value", "PIPX_BIN_DIR")
+	out, err := utils.CommandOutput(cmd)
+	if err != nil {
+		return "", errors.New("failed to determine where pipx installs Semgrep")
+	}
+
+	return filepath.Join(strings.TrimSpace(string(out)), "semgrep"), nil
+}
+
+func installSemgrepWithPipx() error {
+	if config.Offline {
+		return installSemgrepFromBundle()
+	}
+
 	cmd := exec.Command("python3", "-m", "pipx", "install", "semgrep")
-	_, err := cmd.Output()
+	_, err := utils.CommandOutput(cmd)
 	if err != nil {
 		return errors.New("Failed to install Semgrep. Make sure that python3 and pipx are installed.")
 	}
 
 	return nil
 }
+
+func installSemgrepFromBundle() error {
+	semgrepWheelsDirPath, err := getSemgrepWheelsDirPath()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("python3", "-m", "pipx", "install", "semgrep")
+	// Environment variables rather than --pip-args because pipx splits the latter at spaces.
+	cmd.Env = append(os.Environ(), "PIP_NO_INDEX=1", "PIP_FIND_LINKS="+semgrepWheelsDirPath)
+	_, err = utils.CommandOutput(cmd)
+	if err != nil {
+		return errors.New("Failed to install Semgrep from the bundle. Make sure that python3 and pipx are installed.")
+	}
+
+	return nil
+}
+
+// Installs the given version, replacing any other version. An empty version means any version.
+func installSemgrepVersion(version string) error {
+	if version == "" {
+		return installSemgrepWithPipx()
+	}
+
+	if config.Offline {
+		return errors.New("cannot choose the version of Semgrep in offline mode")
+	}
+
+	if !versionRegex.MatchString(version) {
+		return errors.New("invalid version of Semgrep: " + version)
+	}
+
+	cmd := exec.Command("python3", "-m", "pipx", "install", "--force", "semgrep=="+version)
+	_, err := utils.CommandOutput(cmd)
+	if err != nil {
+		return errors.New("Failed to install Semgrep " + version + ". Make sure that python3 and pipx are installed.")
+	}
+
+	return nil
+}
+
+// Contrary to the dependencies of the manifest, Semgrep is not pinned and is updated to the latest version.
+func updateSemgrep() error {
+	err := installSemgrepWithPipx()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("python3", "-m", "pipx", "upgrade", "semgrep")
+	_, err = utils.CommandOutput(cmd)
+	if err != nil {
+		return errors.New("Failed to update Semgrep. Make sure that python3 and pipx are installed.")
+	}
+
+	return nil
+}
@@ -1,66 +1,351 @@
 package dependencies
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/codeclysm/extract/v3"
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/utils"
 )
 
-const DependenciesDir = "/tmp/secguroDependencies"
+const directoryPermissions = 0700
 
-func extractGzDependency(name string) error {
-	file, _ := os.Open(DependenciesDir + "/" + name + ".tar.gz")
-	return extract.Gz(context.Background(), file, DependenciesDir+"/"+name, nil)
+// Written to the installation directory once the installation is complete.
+const installationRecordFileName = ".secguro-dependency.json"
+
+type installationRecord struct {
+	Name    string
+	Version string
+	Url     string
+	Sha256  string            // of the downloaded artifact
+	Files   map[string]string // SHA-256 of the installed files by path relative to the installation directory
+	Bundle  string            // path of the bundle that it has been imported from, if any
 }
 
-func extractZipDependency(name string) error {
-	file, _ := os.Open(DependenciesDir + "/" + name + ".zip")
-	return extract.Zip(context.Background(), file, DependenciesDir+"/"+name, nil)
+/**
+ * Dependencies are stored in the user's cache directory (e.g. $XDG_CACHE_HOME/secguro/dependencies)
+ * unless a different directory is given through an environment variable.
+ */
+func GetDirPath() (string, error) {
+	if dirPath := os.Getenv(config.DependenciesDirEnvVarName); dirPath != "" {
+		return dirPath, nil
+	}
+
+	cacheDirPath, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDirPath, "secguro", "dependencies"), nil
 }
 
-func downloadDependency(filePath string, url string) error {
-	dirPath := filepath.Dir(filePath)
+// Each version is installed into a directory of its own.
+func getInstallDirPath(dependency Dependency) (string, error) {
+	dirPath, err := GetDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dirPath, dependency.Name+"-"+dependency.Version), nil
+}
+
+func isInstalled(dependency Dependency) (bool, error) {
+	installDirPath, err := getInstallDirPath(dependency)
+	if err != nil {
+		return false, err
+	}
+
+	return utils.DoesFileExist(filepath.Join(installDirPath, installationRecordFileName))
+}
+
+/**
+ * Downloads and verifies the dependency and extracts it into a temporary directory that is
+ * only renamed to the installation directory once complete. Interrupted installations
+ * therefore never leave a directory behind that is mistaken for an installation.
+ */
+func install(dependency Dependency) error {
+	err := ensureBundleImported()
+	if err != nil {
+		return err
+	}
+
+	installed, err := isInstalled(dependency)
+	if err != nil || installed {
+		return err
+	}
+
+	if config.Offline {
+		return errors.New(dependency.Name + " is not installed; " + offlineHint)
+	}
+
+	artifact, ok := dependency.getArtifact()
+	if !ok {
+		return fmt.Errorf("%s is not available for %s/%s", dependency.Name, runtime.GOOS, runtime.GOARCH)
+	}
 
-	const directoryPermissions = 0700
-	err := os.MkdirAll(dirPath, directoryPermissions)
+	if artifact.Sha256 == "" && artifact.ChecksumsUrl == "" {
+		return fmt.Errorf("no checksum is known for %s %s; install it with secguro deps install "+
+			"--version %s=%s --checksum %s=<sha256>", dependency.Name, dependency.Version,
+			dependency.Name, dependency.Version, dependency.Name)
+	}
+
+	dirPath, err := GetDirPath()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dirPath, directoryPermissions)
 	if err != nil {
 		return err
 	}
 
-	return downloadFile(filePath, url)
+	downloadFilePath, sha256Sum, err := downloadFile(dirPath, artifact.Url)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", dependency.Name, err)
+	}
+	defer os.Remove(downloadFilePath)
+
+	err = verifyChecksum(artifact, sha256Sum)
+	if err != nil {
+		return fmt.Errorf("failed to verify download of %s: %w", dependency.Name, err)
+	}
+
+	tmpInstallDirPath, err := os.MkdirTemp(dirPath, ".install-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpInstallDirPath)
+
+	err = extractArtifact(dependency, downloadFilePath, tmpInstallDirPath)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", dependency.Name, err)
+	}
+
+	fileChecksums, err := getFileChecksums(tmpInstallDirPath)
+	if err != nil {
+		return err
+	}
+
+	err = writeInstallationRecord(tmpInstallDirPath, installationRecord{
+		Name:    dependency.Name,
+		Version: dependency.Version,
+		Url:     artifact.Url,
+		Sha256:  sha256Sum,
+		Files:   fileChecksums,
+		Bundle:  "",
+	})
+	if err != nil {
+		return err
+	}
+
+	return moveIntoPlace(dependency, tmpInstallDirPath)
 }
 
-// https://stackoverflow.com/a/33853856
-func downloadFile(filePath string, url string) error {
-	// Create the file
-	out, err := os.Create(filePath)
+func moveIntoPlace(dependency Dependency, tmpInstallDirPath string) error {
+	installDirPath, err := getInstallDirPath(dependency)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
 
-	// Get the data
-	resp, err := http.Get(url) //nolint: noctx
+	// Another secguro process may have completed the installation in the meantime.
+	installed, err := isInstalled(dependency)
+	if err != nil || installed {
+		return err
+	}
+
+	// Only a directory without installation record is left, which is not an installation.
+	err = os.RemoveAll(installDirPath)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpInstallDirPath, installDirPath)
 	if err != nil {
+		// The other process may also have been faster in between.
+		if installed, _ := isInstalled(dependency); installed {
+			return nil
+		}
+
 		return err
 	}
+
+	return nil
+}
+
+// Downloads into a temporary file in dirPath and returns its path and SHA-256 checksum.
+func downloadFile(dirPath string, url string) (string, string, error) {
+	file, err := os.CreateTemp(dirPath, ".download-")
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	sha256Sum, err := downloadTo(file, url)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", "", err
+	}
+
+	return file.Name(), sha256Sum, nil
+}
+
+func downloadTo(writer io.Writer, url string) (string, error) {
+	if config.Offline {
+		return "", errors.New("cannot download " + url + " in offline mode")
+	}
+
+	resp, err := http.Get(url) //nolint: noctx
+	if err != nil {
+		return "", err
+	}
 	defer resp.Body.Close()
 
-	// Check server response
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
+		return "", fmt.Errorf("bad status: %s", resp.Status)
 	}
 
-	// Writer the body to file
-	_, err = io.Copy(out, resp.Body)
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(writer, hash), resp.Body)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func verifyChecksum(artifact Artifact, sha256Sum string) error {
+	expectedSha256Sum := artifact.Sha256
+	if expectedSha256Sum == "" {
+		var err error
+		expectedSha256Sum, err = getPublishedChecksum(artifact)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !strings.EqualFold(expectedSha256Sum, sha256Sum) {
+		return fmt.Errorf("checksum mismatch (expected %s, got %s)", expectedSha256Sum, sha256Sum)
 	}
 
 	return nil
 }
+
+// Looks up the checksum of the artifact in a checksums file of the format of sha256sum.
+func getPublishedChecksum(artifact Artifact) (string, error) {
+	var checksums strings.Builder
+	_, err := downloadTo(&checksums, artifact.ChecksumsUrl)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksums: %w", err)
+	}
+
+	artifactFileName := path.Base(artifact.Url)
+	scanner := bufio.NewScanner(strings.NewReader(checksums.String()))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		const fieldCount = 2
+		if len(fields) == fieldCount && strings.TrimPrefix(fields[1], "*") == artifactFileName {
+			return fields[0], nil
+		}
+	}
+
+	return "", errors.New("no published checksum for " + artifactFileName)
+}
+
+func extractArtifact(dependency Dependency, downloadFilePath string, targetDirPath string) error {
+	if dependency.ArchiveType == archiveTypeNone {
+		return os.Rename(downloadFilePath, filepath.Join(targetDirPath, dependency.FileName))
+	}
+
+	file, err := os.Open(downloadFilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch dependency.ArchiveType {
+	case archiveTypeTarGz:
+		return extract.Gz(context.Background(), file, targetDirPath, nil)
+	case archiveTypeZip:
+		return extract.Zip(context.Background(), file, targetDirPath, nil)
+	default:
+		return errors.New("unsupported archive type: " + dependency.ArchiveType)
+	}
+}
+
+func getFileChecksums(installDirPath string) (map[string]string, error) {
+	fileChecksums := make(map[string]string)
+
+	err := filepath.WalkDir(installDirPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+
+		relativeFilePath, err := filepath.Rel(installDirPath, filePath)
+		if err != nil || relativeFilePath == installationRecordFileName {
+			return err
+		}
+
+		fileChecksums[relativeFilePath], err = getFileChecksum(filePath)
+
+		return err
+	})
+
+	return fileChecksums, err
+}
+
+func getFileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func writeInstallationRecord(installDirPath string, record installationRecord) error {
+	recordJson, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	const filePermissions = 0600
+
+	return os.WriteFile(filepath.Join(installDirPath, installationRecordFileName), recordJson, filePermissions)
+}
+
+func readInstallationRecord(installDirPath string) (installationRecord, error) {
+	var record installationRecord
+
+	recordJson, err := os.ReadFile(filepath.Join(installDirPath, installationRecordFileName))
+	if err != nil {
+		return record, err
+	}
+
+	err = json.Unmarshal(recordJson, &record)
+
+	return record, err
+}
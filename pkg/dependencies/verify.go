@@ -0,0 +1,112 @@
+package dependencies
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+/**
+ * Checks the installed dependencies against the checksums recorded on installation
+ * and, where pinned, against the checksums of the manifest.
+ */
+func CommandVerify() error {
+	failedDependencyNames := make([]string, 0)
+
+	for _, manifestDependency := range manifest {
+		if _, ok := manifestDependency.getArtifact(); !ok {
+			continue
+		}
+
+		dependency, err := getSelectedDependency(manifestDependency)
+		if err != nil {
+			return err
+		}
+
+		problems, err := verify(dependency)
+		if err != nil {
+			return err
+		}
+
+		status := "ok"
+		switch {
+		case problems == nil:
+			status = "not installed"
+		case len(problems) > 0:
+			status = "FAILED (" + strings.Join(problems, "; ") + ")"
+			failedDependencyNames = append(failedDependencyNames, dependency.Name)
+		}
+
+		fmt.Println(dependency.Name + " " + dependency.Version + ": " + status)
+	}
+
+	if len(failedDependencyNames) > 0 {
+		dirPath, err := GetDirPath()
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("verification failed for: %s (delete their directories in %s to reinstall them)",
+			strings.Join(failedDependencyNames, ", "), dirPath)
+	}
+
+	return nil
+}
+
+// Returns nil if the dependency is not installed and an empty slice if there are no problems.
+func verify(dependency Dependency) ([]string, error) {
+	installed, err := isInstalled(dependency)
+	if err != nil || !installed {
+		return nil, err
+	}
+
+	installDirPath, err := getInstallDirPath(dependency)
+	if err != nil {
+		return nil, err
+	}
+
+	return getInstallationProblems(dependency, installDirPath)
+}
+
+// Compares the files in the installation directory with the installation record in it.
+func getInstallationProblems(dependency Dependency, installDirPath string) ([]string, error) {
+	record, err := readInstallationRecord(installDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := make([]string, 0)
+
+	if record.Name != dependency.Name || record.Version != dependency.Version {
+		problems = append(problems, "installation record belongs to "+record.Name+" "+record.Version)
+	}
+
+	artifact, _ := dependency.getArtifact()
+	if artifact.Sha256 != "" && !strings.EqualFold(artifact.Sha256, record.Sha256) {
+		problems = append(problems, "download does not match the pinned checksum")
+	}
+
+	fileChecksums, err := getFileChecksums(installDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filePath := range slices.Sorted(maps.Keys(record.Files)) {
+		fileChecksum, exists := fileChecksums[filePath]
+		switch {
+		case !exists:
+			problems = append(problems, "missing "+filePath)
+		case fileChecksum != record.Files[filePath]:
+			problems = append(problems, "modified "+filePath)
+		}
+	}
+
+	for _, filePath := range slices.Sorted(maps.Keys(fileChecksums)) {
+		if _, isRecorded := record.Files[filePath]; !isRecorded {
+			problems = append(problems, "unexpected "+filePath)
+		}
+	}
+
+	return problems, nil
+}
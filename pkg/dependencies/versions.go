@@ -0,0 +1,90 @@
+package dependencies
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+/**
+ * Versions chosen with `secguro deps install --version`, by name of the dependency.
+ * Dependencies without entry are used in the version of the manifest.
+ */
+const selectedVersionsFileName = "versions.json"
+
+func readSelectedVersions() (map[string]string, error) {
+	selectedVersions := make(map[string]string)
+
+	dirPath, err := GetDirPath()
+	if err != nil {
+		return nil, err
+	}
+
+	selectedVersionsJson, err := os.ReadFile(filepath.Join(dirPath, selectedVersionsFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return selectedVersions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(selectedVersionsJson, &selectedVersions)
+
+	return selectedVersions, err
+}
+
+func writeSelectedVersions(selectedVersions map[string]string) error {
+	dirPath, err := GetDirPath()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dirPath, directoryPermissions)
+	if err != nil {
+		return err
+	}
+
+	selectedVersionsJson, err := json.MarshalIndent(selectedVersions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	const filePermissions = 0600
+
+	return os.WriteFile(filepath.Join(dirPath, selectedVersionsFileName), selectedVersionsJson, filePermissions)
+}
+
+// Returns the dependency in the version that is to be used.
+func getSelectedDependency(dependency Dependency) (Dependency, error) {
+	selectedVersions, err := readSelectedVersions()
+	if err != nil {
+		return Dependency{}, err
+	}
+
+	selectedVersion, ok := selectedVersions[dependency.Name]
+	if !ok {
+		return dependency, nil
+	}
+
+	return dependency.withVersion(selectedVersion, "")
+}
+
+func installSelected(dependency Dependency) error {
+	selectedDependency, err := getSelectedDependency(dependency)
+	if err != nil {
+		return err
+	}
+
+	return install(selectedDependency)
+}
+
+func getSelectedInstallDirPath(dependency Dependency) (string, error) {
+	selectedDependency, err := getSelectedDependency(dependency)
+	if err != nil {
+		return "", err
+	}
+
+	return getInstallDirPath(selectedDependency)
+}
@@ -1,18 +1,21 @@
 package dependencycheck
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/secguro/secguro-cli/pkg/config"
 	"github.com/secguro/secguro-cli/pkg/dependencies"
+	"github.com/secguro/secguro-cli/pkg/logging"
 	"github.com/secguro/secguro-cli/pkg/types"
+	"github.com/secguro/secguro-cli/pkg/utils"
 )
 
+const detectorName = "dependencycheck"
+
 type Meta_DependencycheckFinding struct {
 	Dependencies []DependencycheckFinding
 }
@@ -23,7 +26,29 @@ type DependencycheckFinding struct {
 }
 
 type DependencycheckFinding_Vulnerabilities struct {
-	Name string
+	Name        string
+	Severity    string
+	Cvssv2      *DependencycheckFinding_Vulnerabilities_Cvssv2
+	Cvssv3      *DependencycheckFinding_Vulnerabilities_Cvssv3
+	Cwes        []string
+	Description string
+	References  []DependencycheckFinding_Vulnerabilities_References
+}
+
+type DependencycheckFinding_Vulnerabilities_Cvssv2 struct {
+	Score    float64
+	Severity string
+}
+
+type DependencycheckFinding_Vulnerabilities_Cvssv3 struct {
+	BaseScore    float64
+	BaseSeverity string
+}
+
+type DependencycheckFinding_Vulnerabilities_References struct {
+	Source string
+	Url    string
+	Name   string
 }
 
 func convertDependencycheckFindingToUnifiedFinding(directoryToScan string,
@@ -49,23 +74,28 @@ func convertDependencycheckFindingToUnifiedFinding(directoryToScan string,
 	packageAndVersionPossiblePrefixed := dependencycheckFinding.FilePath[separatorIndex+len(separator):]
 	packageAndVersion := strings.TrimPrefix(packageAndVersionPossiblePrefixed, "/")
 
+	vulnerability := dependencycheckFinding.Vulnerabilities[vulnerabilityIndex]
+
 	return types.UnifiedFinding{
-		Detector:             "dependencycheck",
+		Detector:             detectorName,
 		IdOnExternalPlatform: nil,
-		Rule:                 dependencycheckFinding.Vulnerabilities[vulnerabilityIndex].Name,
+		Rule:                 vulnerability.Name,
 		File:                 file,
 		LineStart:            -1,
 		LineEnd:              -1,
 		ColumnStart:          -1,
 		ColumnEnd:            -1,
 		Match:                packageAndVersion,
-		Hint:                 "",
-		Severity:             "WARNING", // TODO: differentiate severity for dependencycheck
+		Hint:                 getHint(vulnerability),
+		Severity:             getSeverity(vulnerability),
 		GitInfo:              nil,
+		Fingerprint:          "",
+		OtherDetections:      nil,
+		Occurrences:          nil,
 	}
 }
 
-func getDependencycheckOutputJson(directoryToScan string, _gitMode bool) ([]byte, error) {
+func getDependencycheckOutputJson(ctx context.Context, directoryToScan string, _gitMode bool) ([]byte, error) {
 	tmpDir, err := os.MkdirTemp("", "")
 	if err != nil {
 		return nil, err
@@ -74,40 +104,59 @@ func getDependencycheckOutputJson(directoryToScan string, _gitMode bool) ([]byte
 	dependencycheckOutputDirPath := tmpDir
 	dependencycheckOutputJsonPath := dependencycheckOutputDirPath + "/dependency-check-report.json"
 
-	// secguro-ignore-next-line
-	cmd := exec.Command(dependencies.DependenciesDir+"/dependencycheck/dependency-check/bin/dependency-check.sh",
+	dependencycheckExecutablePath, err := dependencies.GetDependencycheckExecutablePath()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
 		"--enableExperimental", // necessary for support of go dependencies
-		"--scan", directoryToScan+"/**/package.json",
-		"--scan", directoryToScan+"/**/package-lock.json",
-		"--scan", directoryToScan+"/**/go.mod", // .sum files are not considered by dependencycheck
+		"--scan", directoryToScan + "/**/package.json",
+		"--scan", directoryToScan + "/**/package-lock.json",
+		"--scan", directoryToScan + "/**/go.mod", // .sum files are not considered by dependencycheck
 		"--format", "JSON", "--out", dependencycheckOutputDirPath,
-		"--nvdApiKey", os.Getenv(config.NvdApiKeyEnvVarName))
-	out, err := cmd.Output()
+	}
+	if config.Offline {
+		offlineArgs, err := getOfflineArgs()
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, offlineArgs...)
+	} else {
+		// secguro-ignore-next-line
+		args = append(args, "--nvdApiKey", os.Getenv(config.NvdApiKeyEnvVarName))
+	}
+
+	cmd := utils.CommandContext(ctx, dependencycheckExecutablePath, args...)
+	out, err := utils.CommandOutput(cmd)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	err = utils.CheckExitCode(detectorName, err)
 	if err != nil {
-		if !config.TolerateDependecycheckErrorExitCodes {
-			fmt.Println("Received output from dependencycheck:")
-			fmt.Println(out)
-			fmt.Println("Received error from dependencycheck:")
-			fmt.Println(err)
+		logging.Verbose("dependencycheck failed", "output", string(out), "error", err)
 
-			return nil, errors.New("dependencycheck failed")
+		if !config.TolerateDependecycheckErrorExitCodes {
+			return nil, err
 		}
 
-		fmt.Println("Received error from dependencycheck but continuing anyway...")
+		logging.Warn("Received error from dependencycheck but continuing anyway (" + err.Error() + ")...")
 	}
 
-	if out == nil {
-		return nil, errors.New("did not receive output from dependencycheck")
+	dependencycheckOutputJson, reportErr := utils.ReadReportFile(detectorName, dependencycheckOutputJsonPath)
+	// Without a report, the error of dependencycheck explains best what went wrong.
+	if reportErr != nil && err != nil {
+		return nil, err
 	}
 
-	dependencycheckOutputJson, err := os.ReadFile(dependencycheckOutputJsonPath)
-
-	return dependencycheckOutputJson, err
+	return dependencycheckOutputJson, reportErr
 }
 
-func getDependencycheckFindingsAsUnifiedLocally(directoryToScan string,
+func getDependencycheckFindingsAsUnifiedLocally(ctx context.Context, directoryToScan string,
 	gitMode bool) ([]types.UnifiedFinding, error) {
-	dependencycheckOutputJson, err := getDependencycheckOutputJson(directoryToScan, gitMode)
+	dependencycheckOutputJson, err := getDependencycheckOutputJson(ctx, directoryToScan, gitMode)
 	if err != nil {
 		return nil, err
 	}
@@ -128,36 +177,62 @@ func getDependencycheckFindingsAsUnifiedLocally(directoryToScan string,
 		}
 	}
 
+	sortBySeverity(unifiedFindings)
+
 	return unifiedFindings, nil
 }
 
-func GetDependencycheckFindingsAsUnified(directoryToScan string, gitMode bool,
-	unifiedFindingsChannel chan types.UnifiedFinding,
-	detectorTerminationChannel chan types.DetectorTermination) {
-	var f func(directoryToScan string, gitMode bool) ([]types.UnifiedFinding, error)
-	if os.Getenv(config.NvdApiKeyEnvVarName) == "" {
-		f = getDependencycheckFindingsAsUnifiedFromServer
-	} else {
-		f = getDependencycheckFindingsAsUnifiedLocally
+type Detector struct{}
+
+func (Detector) Name() string {
+	return detectorName
+}
+
+func (Detector) Install() error {
+	// dependencycheck is run on the server if no NVD API key is available (except in offline mode).
+	if isUsingDependencycheckOnServer() {
+		return nil
 	}
 
-	unifiedFindings, err := f(directoryToScan, gitMode)
-	if err != nil {
-		fmt.Println(err)
-		detectorTerminationChannel <- types.DetectorTermination{
-			Detector:   "dependencycheck",
-			Successful: false,
-		}
+	return dependencies.InstallDependencycheck()
+}
+
+func (Detector) Scan(ctx context.Context, scanOptions types.ScanOptions) ([]types.UnifiedFinding, error) {
+	if scanOptions.IsRestrictedToChangedFiles() && !includesManifestFile(scanOptions.ChangedFiles) {
+		return make([]types.UnifiedFinding, 0), nil
+	}
 
-		return
+	if isUsingDependencycheckOnServer() {
+		return getDependencycheckFindingsAsUnifiedFromServer(ctx, scanOptions.DirectoryToScan, scanOptions.GitMode)
 	}
 
-	for _, unifiedFinding := range unifiedFindings {
-		unifiedFindingsChannel <- unifiedFinding
+	return getDependencycheckFindingsAsUnifiedLocally(ctx, scanOptions.DirectoryToScan, scanOptions.GitMode)
+}
+
+func includesManifestFile(filePaths []string) bool {
+	for _, filePath := range filePaths {
+		if isManifestFile(filepath.Base(filePath)) {
+			return true
+		}
 	}
 
-	detectorTerminationChannel <- types.DetectorTermination{
-		Detector:   "dependencycheck",
-		Successful: true,
+	return false
+}
+
+// Uses the vulnerability data of the bundle and disables all analyzers that query online services.
+func getOfflineArgs() ([]string, error) {
+	dataDirPath, err := dependencies.GetDependencycheckDataDirPath()
+	if err != nil {
+		return nil, err
 	}
+
+	return []string{
+		"--data", dataDirPath, "--noupdate",
+		"--disableOssIndex", "--disableCentral", "--disableRetireJS", "--disableHostedSuppressions",
+		"--disableNodeAudit", "--disableYarnAudit", "--disablePnpmAudit",
+	}, nil
+}
+
+func isUsingDependencycheckOnServer() bool {
+	return !config.Offline && os.Getenv(config.NvdApiKeyEnvVarName) == ""
 }
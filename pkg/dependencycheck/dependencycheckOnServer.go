@@ -1,48 +1,32 @@
 package dependencycheck
 
 import (
+	"context"
 	"errors"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/go-resty/resty/v2"
-	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/api"
 	"github.com/secguro/secguro-cli/pkg/types"
 )
 
-const endpointPostDependencycheckScan = "dependencycheckScans"
-
-func getDependencycheckFindingsAsUnifiedFromServer(directoryToScan string,
+func getDependencycheckFindingsAsUnifiedFromServer(ctx context.Context, directoryToScan string,
 	_gitMode bool) ([]types.UnifiedFinding, error) {
 	manifestFiles, err := getManifestFiles(directoryToScan)
 	if err != nil {
 		return nil, err
 	}
 
-	urlEndpointPostDependencycheckScan := config.ServerUrl + "/" + endpointPostDependencycheckScan
-
 	dependencycheckScanPostReq := types.DependencycheckScanPostReq{
 		ManifestFiles: manifestFiles,
 	}
 
-	result := types.DependencycheckScanRes{} //nolint: exhaustruct
-	client := resty.New()
-	response, err := client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(dependencycheckScanPostReq).
-		SetResult(&result).
-		Post(urlEndpointPostDependencycheckScan)
-
+	result, err := api.PostDependencycheckScan(ctx, dependencycheckScanPostReq)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.StatusCode() != http.StatusOK {
-		return nil, errors.New("received bad status code")
-	}
-
 	return result.UnifiedFindings, nil
 }
 
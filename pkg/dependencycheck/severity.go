@@ -0,0 +1,97 @@
+package dependencycheck
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+// Maximum number of references to include in the hint of a finding.
+const maxNumberOfReferencesInHint = 3
+
+/**
+ * The severity as dependency-check rates the vulnerability: the CVSSv3 rating over the CVSSv2
+ * rating over the severity given by the source of the vulnerability (e.g. npm audit). Ratings
+ * that are missing from the report are derived from the scores.
+ */
+func getSeverity(vulnerability DependencycheckFinding_Vulnerabilities) string {
+	if vulnerability.Cvssv3 != nil {
+		if vulnerability.Cvssv3.BaseSeverity != "" {
+			return vulnerability.Cvssv3.BaseSeverity
+		}
+
+		return getSeverityFromCvssv3Score(vulnerability.Cvssv3.BaseScore).String()
+	}
+
+	if vulnerability.Cvssv2 != nil {
+		if vulnerability.Cvssv2.Severity != "" {
+			return vulnerability.Cvssv2.Severity
+		}
+
+		return getSeverityFromCvssv2Score(vulnerability.Cvssv2.Score).String()
+	}
+
+	return vulnerability.Severity
+}
+
+// https://nvd.nist.gov/vuln-metrics/cvss (CVSS v3.x ratings)
+func getSeverityFromCvssv3Score(score float64) types.Severity {
+	switch {
+	case score >= 9.0: //nolint: mnd
+		return types.SeverityCritical
+	case score >= 7.0: //nolint: mnd
+		return types.SeverityHigh
+	case score >= 4.0: //nolint: mnd
+		return types.SeverityMedium
+	case score > 0:
+		return types.SeverityLow
+	default:
+		return types.SeverityInfo
+	}
+}
+
+// https://nvd.nist.gov/vuln-metrics/cvss (CVSS v2.0 ratings; there is no critical rating)
+func getSeverityFromCvssv2Score(score float64) types.Severity {
+	switch {
+	case score >= 7.0: //nolint: mnd
+		return types.SeverityHigh
+	case score >= 4.0: //nolint: mnd
+		return types.SeverityMedium
+	case score > 0:
+		return types.SeverityLow
+	default:
+		return types.SeverityInfo
+	}
+}
+
+func getHint(vulnerability DependencycheckFinding_Vulnerabilities) string {
+	hint := strings.TrimSpace(vulnerability.Description)
+
+	if len(vulnerability.Cwes) > 0 {
+		hint += "\nCWEs: " + strings.Join(vulnerability.Cwes, ", ")
+	}
+
+	referenceUrls := make([]string, 0)
+	for _, reference := range vulnerability.References {
+		if len(referenceUrls) == maxNumberOfReferencesInHint {
+			break
+		}
+		if reference.Url != "" {
+			referenceUrls = append(referenceUrls, reference.Url)
+		}
+	}
+	if len(referenceUrls) > 0 {
+		hint += "\nreferences: " + strings.Join(referenceUrls, ", ")
+	}
+
+	return strings.TrimSpace(hint)
+}
+
+// Sorts findings so that the most severe vulnerabilities come first.
+func sortBySeverity(unifiedFindings []types.UnifiedFinding) {
+	sort.SliceStable(unifiedFindings, func(i, j int) bool {
+		return types.NormalizeSeverity(unifiedFindings[i].Severity) >
+			types.NormalizeSeverity(unifiedFindings[j].Severity)
+	})
+}
@@ -0,0 +1,47 @@
+package detectors
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/dependencycheck"
+	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/gitleaks"
+	"github.com/secguro/secguro-cli/pkg/semgrep"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+// Adding a detector only requires implementing types.Detector and adding it here.
+var registeredDetectors = []types.Detector{
+	gitleaks.Detector{},
+	semgrep.Detector{},
+	dependencycheck.Detector{},
+}
+
+func GetAll() []types.Detector {
+	return registeredDetectors
+}
+
+func GetNames() []string {
+	return functional.Map(registeredDetectors, func(detector types.Detector) string {
+		return detector.Name()
+	})
+}
+
+func GetEnabled(disabledDetectors []string) []types.Detector {
+	return functional.Filter(registeredDetectors, func(detector types.Detector) bool {
+		return !functional.ArrayIncludes(disabledDetectors, detector.Name())
+	})
+}
+
+func ValidateDetectorNames(detectorNames []string) error {
+	validDetectorNames := GetNames()
+	for _, detectorName := range detectorNames {
+		if !functional.ArrayIncludes(validDetectorNames, detectorName) {
+			return errors.New("unknown detector: " + detectorName +
+				" (valid detectors: " + strings.Join(validDetectorNames, ",") + ")")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+/**
+ * Sets the Fingerprint of each finding. The fingerprint is a hash of the detector,
+ * the rule, the file path, the match and the content of the lines of the finding.
+ * Line and column numbers are deliberately not part of it so that it does not change
+ * when lines are inserted above a finding. Whitespace is normalized so that
+ * reformatting does not change it either.
+ */
+func AddFingerprints(directoryToScan string, unifiedFindings []types.UnifiedFinding) []types.UnifiedFinding {
+	fileLinesCache := make(map[string][]string)
+
+	result := make([]types.UnifiedFinding, 0, len(unifiedFindings))
+	for _, unifiedFinding := range unifiedFindings {
+		unifiedFinding.Fingerprint = getFingerprint(unifiedFinding,
+			getContext(directoryToScan, unifiedFinding, fileLinesCache))
+		result = append(result, unifiedFinding)
+	}
+
+	return result
+}
+
+func getFingerprint(unifiedFinding types.UnifiedFinding, context string) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		unifiedFinding.Detector,
+		unifiedFinding.Rule,
+		GetFile(unifiedFinding),
+		normalizeWhitespace(unifiedFinding.Match),
+		context,
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Returns the normalized lines of the finding or an empty string if they are unknown
+// (e.g. for dependencycheck findings or findings only present in the git history).
+func getContext(directoryToScan string, unifiedFinding types.UnifiedFinding,
+	fileLinesCache map[string][]string) string {
+	if unifiedFinding.File == "" || unifiedFinding.LineStart < 1 {
+		return ""
+	}
+
+	lines, ok := fileLinesCache[unifiedFinding.File]
+	if !ok {
+		content, err := os.ReadFile(filepath.Join(directoryToScan, unifiedFinding.File))
+		if err == nil {
+			lines = strings.Split(string(content), "\n")
+		}
+		fileLinesCache[unifiedFinding.File] = lines
+	}
+
+	lineEnd := max(unifiedFinding.LineEnd, unifiedFinding.LineStart)
+	if lineEnd > len(lines) {
+		return ""
+	}
+
+	return normalizeWhitespace(strings.Join(lines[unifiedFinding.LineStart-1:lineEnd], "\n"))
+}
+
+func normalizeWhitespace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// Findings that only exist in the git history do not have a file in the
+// working directory; use their historical location instead.
+func GetFile(unifiedFinding types.UnifiedFinding) string {
+	if unifiedFinding.File == "" && unifiedFinding.GitInfo != nil {
+		return "/" + unifiedFinding.GitInfo.File
+	}
+
+	return unifiedFinding.File
+}
@@ -1,11 +1,15 @@
 package fix
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/logging"
 	"github.com/secguro/secguro-cli/pkg/output"
 	"github.com/secguro/secguro-cli/pkg/scan"
 	"github.com/secguro/secguro-cli/pkg/types"
@@ -172,12 +176,30 @@ var actionPastFixSelection func() error = nil
 
 var showProblemsList func() error = nil
 
-func CommandFix(directoryToScan string, gitMode bool, disabledDetectors []string) error {
-	unifiedFindingsNotIgnored, _, err := scan.PerformScan(directoryToScan, gitMode, disabledDetectors)
+func CommandFix(ctx context.Context, scanOptions types.ScanOptions,
+	disabledDetectors []string, timeouts scan.Timeouts, includeHandled bool) error {
+	unifiedFindingsNotIgnored, _, err := scan.PerformScan(ctx, scanOptions, disabledDetectors, timeouts)
 	if err != nil {
 		return err
 	}
 
+	directoryToScan := scanOptions.DirectoryToScan
+
+	if !includeHandled {
+		unifiedFindingsNotHandled, err := getFindingsNotHandled(directoryToScan, unifiedFindingsNotIgnored)
+		if err != nil {
+			return err
+		}
+
+		handledCount := len(unifiedFindingsNotIgnored) - len(unifiedFindingsNotHandled)
+		if handledCount > 0 {
+			logging.Info(fmt.Sprintf("Omitting %d findings that have already been handled "+
+				"(use --include-handled to show them)", handledCount))
+		}
+
+		unifiedFindingsNotIgnored = unifiedFindingsNotHandled
+	}
+
 	showProblemsList = func() error {
 		model := newModel(directoryToScan, unifiedFindingsNotIgnored)
 		if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
@@ -196,11 +218,26 @@ func CommandFix(directoryToScan string, gitMode bool, disabledDetectors []string
 	return showProblemsList()
 }
 
+// Remembers the finding as handled unless the user went back to the list of findings.
 func fixUnifiedFinding(directoryToScan string,
 	previousStep func() error, unifiedFinding types.UnifiedFinding) error {
+	wentBack := false
+	previousStepTracked := func() error {
+		wentBack = true
+
+		return previousStep()
+	}
+
+	var err error
 	if scan.IsSecretDetectionRule(unifiedFinding.Rule) {
-		return fixSecret(directoryToScan, previousStep, unifiedFinding)
+		err = fixSecret(directoryToScan, previousStepTracked, unifiedFinding)
+	} else {
+		err = fixProblemViaAi(directoryToScan, previousStepTracked, unifiedFinding)
+	}
+
+	if err != nil || wentBack {
+		return err
 	}
 
-	return fixProblemViaAi(directoryToScan, previousStep, unifiedFinding)
+	return markFindingAsHandled(directoryToScan, unifiedFinding)
 }
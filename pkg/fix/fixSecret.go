@@ -2,12 +2,12 @@ package fix
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"os/exec"
 
 	"github.com/secguro/secguro-cli/pkg/dependencies"
 	"github.com/secguro/secguro-cli/pkg/ignoring"
+	"github.com/secguro/secguro-cli/pkg/logging"
 	"github.com/secguro/secguro-cli/pkg/types"
 )
 
@@ -111,7 +111,7 @@ func fixSecretStep3(directoryToScan string, previousStep func() error, secret st
 }
 
 func addSecretToIgnoreList(directoryToScan string, secret string) error {
-	fmt.Print("Adding secret to ignore list...")
+	logging.Progress("Adding secret to ignore list")
 
 	const filePermissions = 0644
 	file, err := os.OpenFile(directoryToScan+"/"+ignoring.SecretsIgnoreFileName,
@@ -127,7 +127,7 @@ func addSecretToIgnoreList(directoryToScan string, secret string) error {
 		return err
 	}
 
-	fmt.Println("done")
+	logging.ProgressDone("done")
 
 	return nil
 }
@@ -204,7 +204,10 @@ func removeSecret(directoryToScan string, secret string) error {
 		return err
 	}
 
-	pathBfg := dependencies.DependenciesDir + "/bfg.jar"
+	pathBfg, err := dependencies.GetBfgJarPath()
+	if err != nil {
+		return err
+	}
 
 	cmd := exec.Command("java", "-jar", pathBfg, "--replace-text", pathReplacementsFile, ".")
 	cmd.Dir = directoryToScan
@@ -10,6 +10,7 @@ import (
 
 	openai "github.com/sashabaranov/go-openai"
 	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/logging"
 	"github.com/secguro/secguro-cli/pkg/output"
 	"github.com/secguro/secguro-cli/pkg/types"
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -50,12 +51,12 @@ func fixProblemViaAiStep2(directoryToScan string, previousStep func() error, ret
 	case 1:
 		return retry()
 	case 2:
-		fmt.Print("Applying fix...")
+		logging.Progress("Applying fix")
 		err := replaceFileContents(directoryToScan, filePath, newFileContent)
 		if err != nil {
 			return err
 		}
-		fmt.Println("done")
+		logging.ProgressDone("done")
 
 		return nil
 	}
@@ -85,7 +86,7 @@ func getFixedFileContentAndDiff(directoryToScan string,
 
 func getFixedFileContentFromChatGptLocally(fileContent string,
 	problemLineNumber int, hint string) (string, error) {
-	fmt.Print("Requesting fix suggestion...")
+	logging.Progress("Requesting fix suggestion")
 
 	// Only submit a small part of the file to ChatGPT because ChatGPT's execution
 	// time mainly depends on the size of the output. Howevr, ChatGPT is bad at
@@ -119,7 +120,7 @@ func getFixedFileContentFromChatGptLocally(fileContent string,
 		return "", err
 	}
 
-	fmt.Println("done")
+	logging.ProgressDone("done")
 
 	newRelevantPart := assimilateEnding(fileContent,
 		removeCodeBlockBackticksIfAny(resp.Choices[0].Message.Content))
@@ -131,6 +132,10 @@ func getFixedFileContentFromChatGptLocally(fileContent string,
 
 func GetFixedFileContentFromChatGpt(fileContent string,
 	problemLineNumber int, hint string) (string, error) {
+	if config.Offline {
+		return "", errors.New("fixing via AI is not available in offline mode")
+	}
+
 	if os.Getenv(config.OpenAiApiKeyEnvVarName) == "" {
 		return getFixedFileContentFromChatGptFromServer(fileContent, problemLineNumber, hint)
 	} else {
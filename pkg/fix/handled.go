@@ -0,0 +1,108 @@
+package fix
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/profile"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+// Located in the secguro config directory; contains one file per scanned directory.
+const handledFindingsDirName = "handledFindings"
+
+type HandledFindings struct {
+	Directory    string
+	Fingerprints []string
+}
+
+func getHandledFindingsFilePath(directoryToScan string) (string, string, error) {
+	absPathDirectoryToScan, err := filepath.Abs(directoryToScan)
+	if err != nil {
+		return "", "", err
+	}
+
+	pathSecguroConfigDir, err := profile.GetSecguroConfigDirPath()
+	if err != nil {
+		return "", "", err
+	}
+
+	hash := sha256.Sum256([]byte(absPathDirectoryToScan))
+	fileName := hex.EncodeToString(hash[:]) + ".json"
+
+	return filepath.Join(pathSecguroConfigDir, handledFindingsDirName, fileName), absPathDirectoryToScan, nil
+}
+
+func readHandledFindings(directoryToScan string) (HandledFindings, error) {
+	handledFindingsFilePath, absPathDirectoryToScan, err := getHandledFindingsFilePath(directoryToScan)
+	if err != nil {
+		return HandledFindings{}, err //nolint: exhaustruct
+	}
+
+	handledFindings := HandledFindings{
+		Directory:    absPathDirectoryToScan,
+		Fingerprints: make([]string, 0),
+	}
+
+	handledFindingsJson, err := os.ReadFile(handledFindingsFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return handledFindings, nil
+	}
+	if err != nil {
+		return handledFindings, err
+	}
+
+	err = json.Unmarshal(handledFindingsJson, &handledFindings)
+
+	return handledFindings, err
+}
+
+func markFindingAsHandled(directoryToScan string, unifiedFinding types.UnifiedFinding) error {
+	handledFindings, err := readHandledFindings(directoryToScan)
+	if err != nil {
+		return err
+	}
+
+	if functional.ArrayIncludes(handledFindings.Fingerprints, unifiedFinding.Fingerprint) {
+		return nil
+	}
+
+	handledFindings.Fingerprints = append(handledFindings.Fingerprints, unifiedFinding.Fingerprint)
+
+	handledFindingsFilePath, _, err := getHandledFindingsFilePath(directoryToScan)
+	if err != nil {
+		return err
+	}
+
+	const directoryPermissions = 0700
+	err = os.MkdirAll(filepath.Dir(handledFindingsFilePath), directoryPermissions)
+	if err != nil {
+		return err
+	}
+
+	handledFindingsJson, err := json.MarshalIndent(handledFindings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	const filePermissions = 0600
+
+	return os.WriteFile(handledFindingsFilePath, handledFindingsJson, filePermissions)
+}
+
+func getFindingsNotHandled(directoryToScan string,
+	unifiedFindings []types.UnifiedFinding) ([]types.UnifiedFinding, error) {
+	handledFindings, err := readHandledFindings(directoryToScan)
+	if err != nil {
+		return nil, err
+	}
+
+	return functional.Filter(unifiedFindings, func(unifiedFinding types.UnifiedFinding) bool {
+		return !functional.ArrayIncludes(handledFindings.Fingerprints, unifiedFinding.Fingerprint)
+	}), nil
+}
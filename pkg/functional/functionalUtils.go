@@ -1,5 +1,7 @@
 package functional
 
+import "sync"
+
 // https://stackoverflow.com/a/71624929
 func Map[T, U any](ts []T, f func(T) U) []U {
 	result := make([]U, len(ts))
@@ -32,6 +34,41 @@ func MapWithError[T, U any](ts []T, f func(T) (U, error)) ([]U, error) {
 	return result, nil
 }
 
+/**
+ * Like MapWithError but calls f from up to workerCount goroutines. The order of the
+ * result corresponds to the order of ts. Returns the first error encountered.
+ */
+func MapWithErrorInParallel[T, U any](ts []T, workerCount int, f func(T) (U, error)) ([]U, error) {
+	result := make([]U, len(ts))
+	errs := make([]error, len(ts))
+
+	indexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	for range max(1, min(workerCount, len(ts))) {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for i := range indexes {
+				result[i], errs[i] = f(ts[i])
+			}
+		}()
+	}
+
+	for i := range ts {
+		indexes <- i
+	}
+	close(indexes)
+	waitGroup.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return make([]U, 0), err
+		}
+	}
+
+	return result, nil
+}
+
 // https://stackoverflow.com/a/37563128
 func Filter[T any](ss []T, test func(T) bool) []T {
 	ret := make([]T, 0)
@@ -0,0 +1,254 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+/**
+ * Answers git info lookups of the findings of a scan. Each file is blamed only once
+ * per revision; all lines of the file are answered from that blame. Safe for use by
+ * multiple goroutines.
+ */
+type BlameCache struct {
+	directoryToScan string
+	gitMode         bool
+
+	mutex                sync.Mutex
+	blamesByKey          map[blameKey]*fileBlame
+	latestCommitHashOnce sync.Once
+	latestCommitHash     string
+	latestCommitHashErr  error
+}
+
+type blameKey struct {
+	revision string
+	filePath string
+	reverse  bool
+}
+
+type fileBlame struct {
+	ready chan struct{} // closed once the blame has been computed
+	// Indexed by line number in the blamed version of the file; nil if the file
+	// is not tracked with git.
+	gitInfosByLine map[int]types.GitInfo
+	err            error
+}
+
+// Information that git blame only outputs for the first line that belongs to a commit.
+type blameCommitInfo struct {
+	authorName         string
+	authorEmailAddress string
+	commitDate         string
+	commitSummary      string
+	file               string
+}
+
+func NewBlameCache(directoryToScan string, gitMode bool) *BlameCache {
+	return &BlameCache{
+		directoryToScan:      directoryToScan,
+		gitMode:              gitMode,
+		mutex:                sync.Mutex{},
+		blamesByKey:          make(map[blameKey]*fileBlame),
+		latestCommitHashOnce: sync.Once{},
+		latestCommitHash:     "",
+		latestCommitHashErr:  nil,
+	}
+}
+
+/**
+ * Returns git info if in git mode; otherwise returns nil.
+ * Empty string for revision means working directory.
+ */
+func (blameCache *BlameCache) GetGitInfo(revision string,
+	filePath string, lineNumber int, reverse bool) (*types.GitInfo, error) {
+	if !blameCache.gitMode {
+		return nil, nil //nolint: nilnil
+	}
+
+	// git blame in reverse does not make sense without a given revision
+	// (e.g. for staged changes that have not been committed yet).
+	if revision == "" && reverse {
+		return nil, nil //nolint: nilnil
+	}
+
+	blame := blameCache.getFileBlame(blameKey{revision: revision, filePath: filePath, reverse: reverse})
+	if blame.err != nil {
+		return nil, blame.err
+	}
+
+	gitInfo, ok := blame.gitInfosByLine[lineNumber]
+	if !ok {
+		return nil, nil //nolint: nilnil
+	}
+
+	return &gitInfo, nil
+}
+
+// Blames the file unless another goroutine has already done so or is doing so.
+func (blameCache *BlameCache) getFileBlame(key blameKey) *fileBlame {
+	blameCache.mutex.Lock()
+	blame, exists := blameCache.blamesByKey[key]
+	if !exists {
+		blame = &fileBlame{ready: make(chan struct{}), gitInfosByLine: nil, err: nil}
+		blameCache.blamesByKey[key] = blame
+	}
+	blameCache.mutex.Unlock()
+
+	if exists {
+		<-blame.ready
+
+		return blame
+	}
+
+	defer close(blame.ready)
+
+	gitBlameOutput, err := getGitBlameOutput(blameCache.directoryToScan, key.revision, key.filePath, key.reverse)
+
+	// If the file is not tracked with git, getGitBlameOutput() returns an error
+	// because `git blame` exits with exit code 128. However, this behavior does
+	// not seem to be documented. Therefore, the exit code is not checked here.
+	// Instead, failure of `git blame` is assumed to always mean that the file
+	// is not tracked with git.
+	if err != nil {
+		return blame
+	}
+
+	blame.gitInfosByLine, blame.err = parseGitBlameOutput(gitBlameOutput)
+
+	return blame
+}
+
+// The hash is only determined once per scan.
+func (blameCache *BlameCache) GetLatestCommitHash() (string, error) {
+	blameCache.latestCommitHashOnce.Do(func() {
+		blameCache.latestCommitHash, blameCache.latestCommitHashErr =
+			GetLatestCommitHash(blameCache.directoryToScan)
+	})
+
+	return blameCache.latestCommitHash, blameCache.latestCommitHashErr
+}
+
+func getGitBlameOutput(directoryToScan string, revision string, filePath string, reverse bool) ([]byte, error) {
+	args := []string{"blame", "-p"}
+	if revision != "" {
+		if reverse {
+			args = append(args, "--reverse", revision+"..HEAD")
+		} else {
+			args = append(args, revision)
+		}
+	}
+
+	args = append(args, "--", filePath)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = directoryToScan
+
+	return cmd.Output()
+}
+
+/**
+ * Parses the porcelain format of git blame. Each line of the file is introduced by a header
+ * line ("<commit hash> <original line> <final line> [<number of lines>]") and followed by its
+ * content prefixed with a tab. Commit details are only output the first time a commit occurs.
+ */
+func parseGitBlameOutput(gitBlameOutput []byte) (map[int]types.GitInfo, error) { //nolint: cyclop
+	gitInfosByLine := make(map[int]types.GitInfo)
+	commitInfosByHash := make(map[string]*blameCommitInfo)
+
+	// Lines are not limited in length, so bufio.Scanner is not suitable.
+	reader := bufio.NewReader(bytes.NewReader(gitBlameOutput))
+
+	var currentCommitHash string
+	var currentCommitInfo *blameCommitInfo
+	var originalLineNumber, finalLineNumber int
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if line == "" && errors.Is(err, io.EOF) {
+			break
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			// The content of the line concludes the information about it.
+			gitInfosByLine[finalLineNumber] = types.GitInfo{
+				CommitHash:         currentCommitHash,
+				CommitDate:         currentCommitInfo.commitDate,
+				AuthorName:         currentCommitInfo.authorName,
+				AuthorEmailAddress: currentCommitInfo.authorEmailAddress,
+				CommitSummary:      currentCommitInfo.commitSummary,
+				File:               currentCommitInfo.file,
+				Line:               originalLineNumber,
+			}
+		case currentCommitInfo == nil || isBlameHeaderLine(line):
+			const minHeaderFieldCount = 3
+			lineFields := strings.Fields(line)
+			if len(lineFields) < minHeaderFieldCount {
+				return nil, errors.New("unexpected git blame output: " + line)
+			}
+
+			currentCommitHash = lineFields[0]
+			originalLineNumber, err = strconv.Atoi(lineFields[1])
+			if err != nil {
+				return nil, err
+			}
+			finalLineNumber, err = strconv.Atoi(lineFields[2])
+			if err != nil {
+				return nil, err
+			}
+
+			currentCommitInfo = commitInfosByHash[currentCommitHash]
+			if currentCommitInfo == nil {
+				currentCommitInfo = &blameCommitInfo{} //nolint: exhaustruct
+				commitInfosByHash[currentCommitHash] = currentCommitInfo
+			}
+		case strings.HasPrefix(line, "summary "):
+			currentCommitInfo.commitSummary = strings.TrimPrefix(line, "summary ")
+		case strings.HasPrefix(line, "author "):
+			currentCommitInfo.authorName = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			currentCommitInfo.authorEmailAddress = strings.TrimSuffix(strings.TrimPrefix(line, "author-mail <"), ">")
+		case strings.HasPrefix(line, "author-time "):
+			authorTimeInt, err := strconv.Atoi(strings.TrimPrefix(line, "author-time "))
+			if err != nil {
+				return nil, err
+			}
+			currentCommitInfo.commitDate = time.Unix(int64(authorTimeInt), 0).UTC().Format(time.RFC3339)
+		case strings.HasPrefix(line, "filename "):
+			currentCommitInfo.file = strings.TrimPrefix(line, "filename ")
+		}
+	}
+
+	return gitInfosByLine, nil
+}
+
+// Header lines start with the full hash of a commit (40 hex characters for SHA-1, 64 for SHA-256).
+func isBlameHeaderLine(line string) bool {
+	hash, _, found := strings.Cut(line, " ")
+	if !found {
+		return false
+	}
+
+	const sha1HashLength = 40
+	const sha256HashLength = 64
+	if len(hash) != sha1HashLength && len(hash) != sha256HashLength {
+		return false
+	}
+
+	return strings.Trim(hash, "0123456789abcdef") == ""
+}
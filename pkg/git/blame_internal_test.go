@@ -0,0 +1,93 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+const commitHash1 = "1111111111111111111111111111111111111111"
+const commitHash2 = "2222222222222222222222222222222222222222"
+
+// Commit details are only output for the first line of each commit; the second line of
+// commitHash1 merely repeats its header. commitHash2 has a previous commit, and the boundary
+// commit commitHash1 is marked as such.
+var gitBlameOutput = strings.Join([]string{
+	commitHash1 + " 1 1 2",
+	"author Alice",
+	"author-mail <alice@example.com>",
+	"author-time 1700000000",
+	"author-tz +0000",
+	"committer Alice",
+	"committer-mail <alice@example.com>",
+	"committer-time 1700000000",
+	"committer-tz +0000",
+	"summary Initial commit",
+	"boundary",
+	"filename old.go",
+	"\tpackage main",
+	commitHash1 + " 2 2",
+	"\t",
+	commitHash2 + " 3 3 1",
+	"author Bob",
+	"author-mail <bob@example.com>",
+	"author-time 1700003600",
+	"author-tz +0100",
+	"committer Bob",
+	"committer-mail <bob@example.com>",
+	"committer-time 1700003600",
+	"committer-tz +0100",
+	"summary Add secret",
+	"previous " + commitHash1 + " old.go",
+	"filename new.go",
+	"\tconst secret = \"abc\"",
+	commitHash1 + " 3 4",
+	"\t// " + commitHash2 + " 1 1 in the content is not a header",
+}, "\n") + "\n"
+
+func TestParseGitBlameOutput(t *testing.T) {
+	t.Parallel()
+
+	gitInfosByLine, err := parseGitBlameOutput([]byte(gitBlameOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gitInfosByLine) != 4 {
+		t.Fatalf("expected git info for 4 lines, got %d", len(gitInfosByLine))
+	}
+
+	testCases := []struct {
+		finalLine    int
+		commitHash   string
+		author       string
+		email        string
+		commitDate   string
+		summary      string
+		file         string
+		originalLine int
+	}{
+		{1, commitHash1, "Alice", "alice@example.com", "2023-11-14T22:13:20Z", "Initial commit", "old.go", 1},
+		{2, commitHash1, "Alice", "alice@example.com", "2023-11-14T22:13:20Z", "Initial commit", "old.go", 2},
+		{3, commitHash2, "Bob", "bob@example.com", "2023-11-14T23:13:20Z", "Add secret", "new.go", 3},
+		{4, commitHash1, "Alice", "alice@example.com", "2023-11-14T22:13:20Z", "Initial commit", "old.go", 3},
+	}
+
+	for _, testCase := range testCases {
+		gitInfo := gitInfosByLine[testCase.finalLine]
+		if gitInfo.CommitHash != testCase.commitHash || gitInfo.AuthorName != testCase.author ||
+			gitInfo.AuthorEmailAddress != testCase.email || gitInfo.CommitDate != testCase.commitDate ||
+			gitInfo.CommitSummary != testCase.summary || gitInfo.File != testCase.file ||
+			gitInfo.Line != testCase.originalLine {
+			t.Errorf("unexpected git info for line %d: %+v", testCase.finalLine, gitInfo)
+		}
+	}
+}
+
+func TestParseGitBlameOutputRejectsMalformedHeader(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseGitBlameOutput([]byte("not a header\n"))
+	if err == nil {
+		t.Fatal("expected error for malformed output")
+	}
+}
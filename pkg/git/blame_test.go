@@ -0,0 +1,102 @@
+package git_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secguro/secguro-cli/pkg/git"
+)
+
+func runGit(tb testing.TB, dirPath string, args ...string) {
+	tb.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dirPath
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=secguro", "GIT_AUTHOR_EMAIL=secguro@example.com",
+		"GIT_COMMITTER_NAME=secguro", "GIT_COMMITTER_EMAIL=secguro@example.com")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		tb.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
+
+/**
+ * Creates a repository with the given number of files that have been changed in the given
+ * number of commits. Each commit appends a line to every file.
+ */
+func createSyntheticRepository(tb testing.TB, fileCount int, commitCount int) string {
+	tb.Helper()
+
+	dirPath := tb.TempDir()
+	runGit(tb, dirPath, "init", "--quiet")
+
+	for commitIndex := range commitCount {
+		for fileIndex := range fileCount {
+			filePath := filepath.Join(dirPath, fmt.Sprintf("file%d.txt", fileIndex))
+			file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+			if err != nil {
+				tb.Fatal(err)
+			}
+
+			_, err = fmt.Fprintf(file, "line %d of file %d\n", commitIndex, fileIndex)
+			file.Close()
+			if err != nil {
+				tb.Fatal(err)
+			}
+		}
+
+		runGit(tb, dirPath, "add", ".")
+		runGit(tb, dirPath, "commit", "--quiet", "-m", fmt.Sprintf("commit %d", commitIndex))
+	}
+
+	return dirPath
+}
+
+func TestBlameCacheAnswersLinesOfFile(t *testing.T) {
+	t.Parallel()
+
+	const commitCount = 3
+	dirPath := createSyntheticRepository(t, 1, commitCount)
+	blameCache := git.NewBlameCache(dirPath, true)
+
+	for line := 1; line <= commitCount; line++ {
+		gitInfo, err := blameCache.GetGitInfo("", "file0.txt", line, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if gitInfo == nil || gitInfo.CommitSummary != fmt.Sprintf("commit %d", line-1) || gitInfo.Line != line {
+			t.Fatalf("unexpected git info for line %d: %+v", line, gitInfo)
+		}
+	}
+
+	gitInfo, err := blameCache.GetGitInfo("", "untracked.txt", 1, false)
+	if err != nil || gitInfo != nil {
+		t.Fatalf("expected no git info for untracked file, got %+v (%v)", gitInfo, err)
+	}
+}
+
+// Looks up every line of every file as findings in all lines would.
+func BenchmarkBlameCache(b *testing.B) {
+	const fileCount = 20
+	const commitCount = 50
+	dirPath := createSyntheticRepository(b, fileCount, commitCount)
+
+	b.ResetTimer()
+	for range b.N {
+		blameCache := git.NewBlameCache(dirPath, true)
+		for fileIndex := range fileCount {
+			for line := 1; line <= commitCount; line++ {
+				_, err := blameCache.GetGitInfo("", fmt.Sprintf("file%d.txt", fileIndex), line, false)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
@@ -2,151 +2,120 @@ package git
 
 import (
 	"bufio"
-	"errors"
-	"fmt"
 	"os/exec"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/secguro/secguro-cli/pkg/functional"
-	"github.com/secguro/secguro-cli/pkg/types"
 )
 
-/**
- * Returns git info if in git mode; otherwise returns nil.
- */
-func GetGitInfo(directoryToScan string, gitMode bool, revision string,
-	filePath string, lineNumber int, reverse bool) (*types.GitInfo, error) {
-	if !gitMode {
-		return nil, nil //nolint: nilnil
+func GetBranchName(directoryToScan string) (string, error) {
+	cmd := exec.Command("git", "branch", "--show-current")
+	cmd.Dir = directoryToScan
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
 	}
 
-	gitBlameOutput, err := getGitBlameOutput(directoryToScan, revision, filePath, lineNumber, reverse)
+	latestCommitHash := strings.TrimSuffix(string(output), "\n")
+
+	return latestCommitHash, nil
+}
 
-	// If the file is not tracked with git, getGitBlameOutput() returns an error
-	// because `git blame` exits with exit code 128. However, this behavior does
-	// not seem to be documented. Therefore, the exit code is not checked here.
-	// Instead, failure of `git blame` is assumed to always mean that the file
-	// is not tracked with git.
+func GetLatestCommitHash(directoryToScan string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = directoryToScan
+	output, err := cmd.Output()
 	if err != nil {
-		return nil, nil //nolint: nilnil, nilerr
+		return "", err
 	}
 
-	gitInfo, err := parseGitBlameOutput(gitBlameOutput)
+	latestCommitHash := strings.TrimSuffix(string(output), "\n")
 
-	return &gitInfo, err
+	return latestCommitHash, nil
 }
 
 /**
- * Empty string for revision means working directory.
+ * Returns the paths (relative to directoryToScan) of files that have been added or
+ * modified since the branch point of HEAD and the given revision, including uncommitted
+ * changes and untracked files. Deleted files are omitted because there is nothing left to scan.
  */
-func getGitBlameOutput(directoryToScan string,
-	revision string, filePath string, lineNumber int, reverse bool) ([]byte, error) {
-	lineRange := fmt.Sprintf("%d,%d", lineNumber, lineNumber)
-
-	args := []string{"blame", "-p", "-L", lineRange}
-	if revision == "" { //nolint: nestif
-		if reverse {
-			return make([]byte, 0), errors.New(
-				"git blame in reverse does not make sense without a given revision")
-		} else { //nolint: staticcheck
-			// args do not need to be modified
-		}
-	} else {
-		if reverse {
-			args = append(args, "--reverse", revision+"..HEAD")
-		} else {
-			args = append(args, revision)
-		}
+func GetFilesChangedSince(directoryToScan string, revision string) ([]string, error) {
+	// Changes on the side of the revision since the branch point are not of interest.
+	cmd := exec.Command("git", "merge-base", revision, "HEAD")
+	cmd.Dir = directoryToScan
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
 	}
 
-	args = append(args, "--", filePath)
+	changedFiles, err := getChangedFiles(directoryToScan, strings.TrimSuffix(string(output), "\n"))
+	if err != nil {
+		return nil, err
+	}
 
-	cmd := exec.Command("git", args...)
+	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard")
 	cmd.Dir = directoryToScan
-	gitBlameOutput, err := cmd.Output()
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, err
+	}
 
-	return gitBlameOutput, err
+	return append(changedFiles, splitLines(output)...), nil
 }
 
-func parseGitBlameOutput(gitBlameOutput []byte) (types.GitInfo, error) { //nolint: cyclop
-	scanner := bufio.NewScanner(strings.NewReader(string(gitBlameOutput)))
-	gitInfo := types.GitInfo{} //nolint: exhaustruct
-	isFirstLine := true
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if isFirstLine {
-			lineFields := strings.Fields(line)
-			gitInfo.CommitHash = lineFields[0]
-			lineNumber, err := strconv.Atoi(lineFields[1])
-			if err != nil {
-				return types.GitInfo{}, err
-			}
-			gitInfo.Line = lineNumber
-			isFirstLine = false
-
-			continue
-		}
-
-		switch {
-		case strings.HasPrefix(line, "summary "):
-			gitInfo.CommitSummary = strings.TrimPrefix(line, "summary ")
-		case strings.HasPrefix(line, "author "):
-			gitInfo.AuthorName = strings.TrimPrefix(line, "author ")
-		case strings.HasPrefix(line, "author-mail "):
-			gitInfo.AuthorEmailAddress = strings.TrimSuffix(strings.TrimPrefix(line, "author-mail <"), ">")
-		case strings.HasPrefix(line, "author-time "):
-			authorTimeString := strings.TrimPrefix(line, "author-time ")
-			authorTimeInt, err := strconv.Atoi(authorTimeString)
-			if err != nil {
-				return types.GitInfo{}, err
-			}
-			authorTime := time.Unix(int64(authorTimeInt), 0)
-			authorTimeFormatted := authorTime.UTC().Format(time.RFC3339)
-			gitInfo.CommitDate = authorTimeFormatted
-		case strings.HasPrefix(line, "filename "):
-			gitInfo.File = strings.TrimPrefix(line, "filename ")
-		}
-	}
+/**
+ * Returns the paths (relative to directoryToScan) of staged files that have been added or modified.
+ */
+func GetStagedFiles(directoryToScan string) ([]string, error) {
+	return getChangedFiles(directoryToScan, "--cached")
+}
 
-	if err := scanner.Err(); err != nil {
-		// Ignore this error because it can only happen with the last line of the output
-		// (the line git blame was called on) as the others are always short enough.
-		// This line is not used by this function.
-		if err.Error() != "bufio.Scanner: token too long" {
-			return types.GitInfo{}, err
-		}
+func getChangedFiles(directoryToScan string, revisionOrOption string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--relative", "--diff-filter=d", revisionOrOption, "--")
+	cmd.Dir = directoryToScan
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
 	}
 
-	return gitInfo, nil
+	return splitLines(output), nil
 }
 
-func GetBranchName(directoryToScan string) (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
+/**
+ * Returns the hashes of the commits reachable from HEAD but not from the given revision.
+ */
+func GetCommitsSince(directoryToScan string, revision string) ([]string, error) {
+	cmd := exec.Command("git", "rev-list", revision+"..HEAD", "--")
 	cmd.Dir = directoryToScan
 	output, err := cmd.Output()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	latestCommitHash := strings.TrimSuffix(string(output), "\n")
+	return splitLines(output), nil
+}
 
-	return latestCommitHash, nil
+func splitLines(output []byte) []string {
+	return functional.Filter(strings.Split(string(output), "\n"), func(line string) bool {
+		return line != ""
+	})
 }
 
-func GetLatestCommitHash(directoryToScan string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = directoryToScan
+/**
+ * Returns the path of the hooks directory, taking core.hooksPath into account.
+ * Relative paths are relative to the given directory.
+ */
+func GetHooksDirPath(directory string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = directory
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
 	}
 
-	latestCommitHash := strings.TrimSuffix(string(output), "\n")
+	hooksDirPath := strings.TrimSuffix(string(output), "\n")
 
-	return latestCommitHash, nil
+	return hooksDirPath, nil
 }
 
 func GetAssetRemoteUrls(directoryToScan string) ([]string, error) {
@@ -1,18 +1,25 @@
 package gitleaks
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"os"
-	"os/exec"
+	"runtime"
+	"strconv"
 
 	"github.com/secguro/secguro-cli/pkg/dependencies"
 	"github.com/secguro/secguro-cli/pkg/functional"
 	"github.com/secguro/secguro-cli/pkg/git"
 	"github.com/secguro/secguro-cli/pkg/types"
+	"github.com/secguro/secguro-cli/pkg/utils"
 )
 
+const detectorName = "gitleaks"
+
+// Exit code for gitleaks to signify that leaks have been found. By default, gitleaks uses 1,
+// which it also uses for fatal errors.
+const leaksExitCode = 3
+
 type GitleaksFinding struct {
 	RuleID      string
 	File        string
@@ -28,22 +35,22 @@ type GitleaksFinding struct {
 	Message     string
 }
 
-func convertGitleaksFindingToUnifiedFinding(directoryToScan string, gitMode bool,
+func convertGitleaksFindingToUnifiedFinding(blameCache *git.BlameCache,
 	gitleaksFinding GitleaksFinding) (types.UnifiedFinding, error) {
-	gitInfo, err := git.GetGitInfo(directoryToScan, gitMode, gitleaksFinding.Commit,
+	gitInfo, err := blameCache.GetGitInfo(gitleaksFinding.Commit,
 		gitleaksFinding.File, gitleaksFinding.StartLine, false)
 	if err != nil {
 		return types.UnifiedFinding{}, err
 	}
 
-	currentLocationGitInfo, err := git.GetGitInfo(directoryToScan, gitMode, gitleaksFinding.Commit,
+	currentLocationGitInfo, err := blameCache.GetGitInfo(gitleaksFinding.Commit,
 		gitleaksFinding.File, gitleaksFinding.StartLine, true)
 	if err != nil {
 		return types.UnifiedFinding{}, err
 	}
 
 	unifiedFinding := types.UnifiedFinding{
-		Detector:             "gitleaks",
+		Detector:             detectorName,
 		IdOnExternalPlatform: nil,
 		Rule:                 gitleaksFinding.RuleID,
 		File:                 "/" + gitleaksFinding.File,
@@ -55,10 +62,13 @@ func convertGitleaksFindingToUnifiedFinding(directoryToScan string, gitMode bool
 		Hint:                 "",
 		Severity:             "ERROR",
 		GitInfo:              gitInfo,
+		Fingerprint:          "",
+		OtherDetections:      nil,
+		Occurrences:          nil,
 	}
 
 	if currentLocationGitInfo != nil {
-		latestCommitHash, err := git.GetLatestCommitHash(directoryToScan)
+		latestCommitHash, err := blameCache.GetLatestCommitHash()
 		if err != nil {
 			return types.UnifiedFinding{}, err
 		}
@@ -78,7 +88,7 @@ func convertGitleaksFindingToUnifiedFinding(directoryToScan string, gitMode bool
 	return unifiedFinding, nil
 }
 
-func getGitleaksOutputJson(directoryToScan string, gitMode bool) ([]byte, error) {
+func getGitleaksOutputJson(ctx context.Context, scanOptions types.ScanOptions) ([]byte, error) {
 	tmpDir, err := os.MkdirTemp("", "")
 	if err != nil {
 		return nil, err
@@ -86,75 +96,82 @@ func getGitleaksOutputJson(directoryToScan string, gitMode bool) ([]byte, error)
 	defer os.RemoveAll(tmpDir)
 	gitleaksOutputJsonPath := tmpDir + "/gitleaksOutput.json"
 
-	cmd := (func() *exec.Cmd {
-		if gitMode {
-			// secguro-ignore-next-line
-			return exec.Command(dependencies.DependenciesDir+"/gitleaks/gitleaks",
-				"detect", "--report-format", "json", "--report-path", gitleaksOutputJsonPath)
-		} else {
-			// secguro-ignore-next-line
-			return exec.Command(dependencies.DependenciesDir+"/gitleaks/gitleaks",
-				"detect", "--no-git", "--report-format", "json", "--report-path", gitleaksOutputJsonPath)
-		}
-	})()
-	cmd.Dir = directoryToScan
-	// Ignore error because this is expected to deliver an exit code not equal to 0 and write to stderr.
-	out, _ := cmd.Output()
-	if out == nil {
-		return nil, errors.New("did not receive output from gitleaks")
+	gitleaksExecutablePath, err := dependencies.GetGitleaksExecutablePath()
+	if err != nil {
+		return nil, err
 	}
 
-	gitleaksOutputJson, err := os.ReadFile(gitleaksOutputJsonPath)
-
-	return gitleaksOutputJson, err
-}
+	// secguro-ignore-next-line
+	cmd := utils.CommandContext(ctx, gitleaksExecutablePath,
+		getGitleaksArgs(scanOptions, gitleaksOutputJsonPath)...)
+	cmd.Dir = scanOptions.DirectoryToScan
+	_, err = utils.CommandOutput(cmd)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 
-func GetGitleaksFindingsAsUnified(directoryToScan string, gitMode bool,
-	unifiedFindingsChannel chan types.UnifiedFinding,
-	detectorTerminationChannel chan types.DetectorTermination) {
-	gitleaksOutputJson, err := getGitleaksOutputJson(directoryToScan, gitMode)
+	err = utils.CheckExitCode(detectorName, err, leaksExitCode)
 	if err != nil {
-		fmt.Println(err)
-		detectorTerminationChannel <- types.DetectorTermination{
-			Detector:   "gitleaks",
-			Successful: false,
-		}
-
-		return
+		return nil, err
 	}
 
-	var gitleaksFindings []GitleaksFinding
-	err = json.Unmarshal(gitleaksOutputJson, &gitleaksFindings)
-	if err != nil {
-		fmt.Println(err)
-		detectorTerminationChannel <- types.DetectorTermination{
-			Detector:   "gitleaks",
-			Successful: false,
-		}
+	return utils.ReadReportFile(detectorName, gitleaksOutputJsonPath)
+}
 
-		return
+func getGitleaksArgs(scanOptions types.ScanOptions, gitleaksOutputJsonPath string) []string {
+	reportArgs := []string{"--report-format", "json", "--report-path", gitleaksOutputJsonPath,
+		"--exit-code", strconv.Itoa(leaksExitCode)}
+
+	switch {
+	case scanOptions.Staged:
+		return append([]string{"protect", "--staged"}, reportArgs...)
+	case scanOptions.Since != "":
+		// Only scan the commits since the given revision.
+		return append([]string{"detect", "--log-opts", scanOptions.Since + "..HEAD"}, reportArgs...)
+	case scanOptions.GitMode:
+		return append([]string{"detect"}, reportArgs...)
+	default:
+		return append([]string{"detect", "--no-git"}, reportArgs...)
 	}
+}
 
-	unifiedFindings, err := functional.MapWithError(gitleaksFindings,
-		func(gitleaksFinding GitleaksFinding) (types.UnifiedFinding, error) {
-			return convertGitleaksFindingToUnifiedFinding(directoryToScan, gitMode, gitleaksFinding)
-		})
-	if err != nil {
-		fmt.Println(err)
-		detectorTerminationChannel <- types.DetectorTermination{
-			Detector:   "gitleaks",
-			Successful: false,
+type Detector struct{}
+
+func (Detector) Name() string {
+	return detectorName
+}
+
+func (Detector) Install() error {
+	return dependencies.InstallGitleaks()
+}
+
+func (Detector) Scan(ctx context.Context, scanOptions types.ScanOptions) ([]types.UnifiedFinding, error) {
+	if scanOptions.Since != "" && !scanOptions.Staged {
+		commitsSince, err := git.GetCommitsSince(scanOptions.DirectoryToScan, scanOptions.Since)
+		if err != nil {
+			return nil, err
 		}
 
-		return
+		if len(commitsSince) == 0 {
+			return make([]types.UnifiedFinding, 0), nil
+		}
 	}
 
-	for _, unifiedFinding := range unifiedFindings {
-		unifiedFindingsChannel <- unifiedFinding
+	gitleaksOutputJson, err := getGitleaksOutputJson(ctx, scanOptions)
+	if err != nil {
+		return nil, err
 	}
 
-	detectorTerminationChannel <- types.DetectorTermination{
-		Detector:   "gitleaks",
-		Successful: true,
+	var gitleaksFindings []GitleaksFinding
+	err = json.Unmarshal(gitleaksOutputJson, &gitleaksFindings)
+	if err != nil {
+		return nil, err
 	}
+
+	blameCache := git.NewBlameCache(scanOptions.DirectoryToScan, scanOptions.GitMode)
+
+	return functional.MapWithErrorInParallel(gitleaksFindings, runtime.NumCPU(),
+		func(gitleaksFinding GitleaksFinding) (types.UnifiedFinding, error) {
+			return convertGitleaksFindingToUnifiedFinding(blameCache, gitleaksFinding)
+		})
 }
@@ -0,0 +1,158 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/detectors"
+	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/git"
+	"github.com/secguro/secguro-cli/pkg/gitleaks"
+	"github.com/secguro/secguro-cli/pkg/output"
+	"github.com/secguro/secguro-cli/pkg/redaction"
+	"github.com/secguro/secguro-cli/pkg/scan"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+const preCommitHookFileName = "pre-commit"
+
+// Identifies hooks created by secguro. Hooks without this marker are never modified.
+const managedHookMarker = "# secguro-managed-hook"
+
+func CommandInstallHooks(directory string, uninstall bool) error {
+	hooksDirPath, err := git.GetHooksDirPath(directory)
+	if err != nil {
+		return errors.New("could not determine git hooks directory (not in a git repository?)")
+	}
+
+	if !filepath.IsAbs(hooksDirPath) {
+		hooksDirPath = filepath.Join(directory, hooksDirPath)
+	}
+
+	hookPath := filepath.Join(hooksDirPath, preCommitHookFileName)
+
+	if uninstall {
+		return uninstallHook(hookPath)
+	}
+
+	return installHook(hookPath)
+}
+
+func installHook(hookPath string) error {
+	isManaged, err := isManagedHook(hookPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil && !isManaged {
+		return errors.New("refusing to overwrite existing hook not created by secguro: " + hookPath)
+	}
+
+	executablePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	const directoryPermissions = 0755
+	err = os.MkdirAll(filepath.Dir(hookPath), directoryPermissions)
+	if err != nil {
+		return err
+	}
+
+	const filePermissions = 0755
+	err = os.WriteFile(hookPath, []byte(getPreCommitHookScript(executablePath)), filePermissions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Installed pre-commit hook: " + hookPath)
+
+	return nil
+}
+
+func uninstallHook(hookPath string) error {
+	isManaged, err := isManagedHook(hookPath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println("No pre-commit hook installed.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !isManaged {
+		return errors.New("refusing to remove existing hook not created by secguro: " + hookPath)
+	}
+
+	err = os.Remove(hookPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Removed pre-commit hook: " + hookPath)
+
+	return nil
+}
+
+func isManagedHook(hookPath string) (bool, error) {
+	content, err := os.ReadFile(hookPath)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.Contains(string(content), managedHookMarker), nil
+}
+
+// Falls back to secguro from $PATH in case the binary that installed the hook has been moved.
+func getPreCommitHookScript(executablePath string) string {
+	return "#!/bin/sh\n" +
+		managedHookMarker + "\n" +
+		"# Remove with: secguro install-hooks --uninstall\n" +
+		"SECGURO='" + strings.ReplaceAll(executablePath, "'", `'\''`) + "'\n" +
+		"if [ ! -x \"$SECGURO\" ]; then\n" +
+		"\tSECGURO=secguro\n" +
+		"fi\n" +
+		"exec \"$SECGURO\" pre-commit\n"
+}
+
+// Run by the pre-commit hook: scans the staged changes for secrets with gitleaks only to stay fast.
+func CommandPreCommit(ctx context.Context, directory string) error {
+	gitleaksDetectorName := gitleaks.Detector{}.Name()
+	disabledDetectors := functional.Filter(detectors.GetNames(), func(detectorName string) bool {
+		return detectorName != gitleaksDetectorName
+	})
+
+	scanOptions := types.ScanOptions{
+		DirectoryToScan: directory,
+		GitMode:         false,
+		Since:           "",
+		Staged:          true,
+		ChangedFiles:    nil,
+		CustomRulePaths: nil,
+	}
+	timeouts := scan.Timeouts{
+		Scan:      0,
+		Detectors: nil,
+	}
+
+	unifiedFindingsNotIgnored, failedDetectors, err := scan.PerformScan(ctx, scanOptions,
+		disabledDetectors, timeouts)
+	if err != nil {
+		return err
+	}
+
+	if len(failedDetectors) != 0 {
+		return errors.New("commit aborted: secret scan failed (use git commit --no-verify to skip the scan)")
+	}
+
+	if len(unifiedFindingsNotIgnored) != 0 {
+		fmt.Println(output.PrintText(redaction.RedactFindings(unifiedFindingsNotIgnored), false))
+
+		return fmt.Errorf("commit aborted: found %d secrets in staged changes "+
+			"(use git commit --no-verify to commit anyway)", len(unifiedFindingsNotIgnored))
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+/**
+ * Leveled logging to stderr. Stdout is reserved for the output requested by the user
+ * (e.g. findings in the requested format) so that it can be piped into other tools.
+ * Progress and other human-readable messages are printed as plain text; verbose and
+ * debug messages are structured (key=value pairs).
+ */
+type Level int
+
+const (
+	LevelQuiet   Level = iota // warnings only
+	LevelInfo                 // progress (default)
+	LevelVerbose              // additionally, detector durations and failures
+	LevelDebug                // additionally, command lines and exit codes of external programs
+)
+
+const slogLevelVerbose = slog.LevelDebug + 2
+
+var level = LevelInfo
+
+var mutex sync.Mutex
+
+// Message of the progress line that is currently open (i.e. not terminated by a line break yet).
+var openProgressMessage = ""
+var isProgressLineOpen = false
+
+var out io.Writer = os.Stderr
+
+var logger = slog.New(slog.NewTextHandler(&lineWriter{}, &slog.HandlerOptions{ //nolint: exhaustruct
+	Level:       slog.LevelDebug,
+	ReplaceAttr: replaceAttr,
+}))
+
+func SetLevel(newLevel Level) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	level = newLevel
+}
+
+func Info(message string) {
+	write(LevelInfo, message+"\n")
+}
+
+// Warnings are shown even in quiet mode.
+func Warn(message string) {
+	write(LevelQuiet, message+"\n")
+}
+
+/**
+ * Starts a progress line ("message...") that is completed by ProgressDone. Messages
+ * logged in between cause the progress line to be repeated before its completion.
+ */
+func Progress(message string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if level < LevelInfo {
+		return
+	}
+
+	closeProgressLine()
+	openProgressMessage = message + "..."
+	isProgressLineOpen = true
+	_, _ = io.WriteString(out, openProgressMessage)
+}
+
+// Appends intermediate progress (e.g. "3/10...") to the open progress line.
+func ProgressUpdate(text string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if level < LevelInfo {
+		return
+	}
+
+	reopenProgressLine()
+	openProgressMessage += text
+	_, _ = io.WriteString(out, text)
+}
+
+func ProgressDone(outcome string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if level < LevelInfo {
+		return
+	}
+
+	reopenProgressLine()
+	_, _ = io.WriteString(out, outcome+"\n")
+	openProgressMessage = ""
+	isProgressLineOpen = false
+}
+
+func Verbose(message string, args ...any) {
+	if !isEnabled(LevelVerbose) {
+		return
+	}
+
+	logger.Log(context.Background(), slogLevelVerbose, message, args...)
+}
+
+func Debug(message string, args ...any) {
+	if !isEnabled(LevelDebug) {
+		return
+	}
+
+	logger.Debug(message, args...)
+}
+
+func isEnabled(minLevel Level) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return level >= minLevel
+}
+
+func write(minLevel Level, text string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if level < minLevel {
+		return
+	}
+
+	closeProgressLine()
+	_, _ = io.WriteString(out, text)
+}
+
+// Must be called with the mutex held.
+func closeProgressLine() {
+	if isProgressLineOpen {
+		_, _ = io.WriteString(out, "\n")
+		isProgressLineOpen = false
+	}
+}
+
+// Must be called with the mutex held.
+func reopenProgressLine() {
+	if !isProgressLineOpen && openProgressMessage != "" {
+		_, _ = io.WriteString(out, openProgressMessage)
+		isProgressLineOpen = true
+	}
+}
+
+// Writes structured log records on lines of their own.
+type lineWriter struct{}
+
+func (lineWriter *lineWriter) Write(p []byte) (int, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	closeProgressLine()
+
+	return out.Write(p)
+}
+
+// Omits the time (which is of little use for a CLI) and names the custom verbose level.
+func replaceAttr(_ []string, attr slog.Attr) slog.Attr {
+	switch attr.Key {
+	case slog.TimeKey:
+		return slog.Attr{} //nolint: exhaustruct
+	case slog.LevelKey:
+		if attr.Value.Any() == slogLevelVerbose {
+			return slog.String(slog.LevelKey, "VERBOSE")
+		}
+	}
+
+	return attr
+}
@@ -1,27 +1,31 @@
 package login
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"net/http"
 	"os"
-	"strings"
 	"time"
 
-	"github.com/go-resty/resty/v2"
+	"github.com/secguro/secguro-cli/pkg/api"
 	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/credentials"
+	"github.com/secguro/secguro-cli/pkg/logging"
+	"github.com/secguro/secguro-cli/pkg/profile"
 	"github.com/secguro/secguro-cli/pkg/types"
-	"github.com/secguro/secguro-cli/pkg/utils"
 )
 
-const deviceTokenFileName = "device_token"
-const secguroConfigDirName = ".secguro"
+type LoginOptions struct {
+	Expiry      time.Duration // time the user has to follow the registration link
+	OpenBrowser bool
+}
 
-const endpointPostDevice = "devices"
-const endpointGetDeviceRegistration = "devices/{deviceId}/registrations"
+func CommandLogin(ctx context.Context, loginOptions LoginOptions) error {
+	deviceName, err := os.Hostname()
+	if err != nil {
+		return err
+	}
 
-func CommandLogin() error {
-	deviceId, deviceToken, err := acquireDeviceIdAndDeviceToken()
+	deviceId, deviceToken, err := acquireDeviceIdAndDeviceToken(ctx, deviceName)
 	if err != nil {
 		return err
 	}
@@ -29,100 +33,63 @@ func CommandLogin() error {
 	loginUrl := fmt.Sprintf("%v/administration/devices?deviceRegistrationId=%d", config.WebappUrl, deviceId)
 	fmt.Println("Please follow this link to register this device: " + loginUrl)
 
-	for {
-		time.Sleep(config.DeviceRegistrationPollingFrequencyInMs * time.Millisecond)
-
-		isRegistered, err := isDeviceRegistered(deviceId)
+	if loginOptions.OpenBrowser {
+		err := openBrowser(loginUrl)
 		if err != nil {
-			return err
-		}
-
-		if isRegistered {
-			break
+			logging.Warn("Failed to open browser: " + err.Error())
 		}
 	}
 
-	err = saveDeviceToken(deviceToken)
+	err = waitForRegistration(ctx, deviceId, loginOptions.Expiry)
 	if err != nil {
-		return err
-	}
-
-	fmt.Println("Device registration successful. Future scans will be visible in the secguro webapp.")
+		// Do not leave an unregistered device behind on the server (even if the user cancelled).
+		_ = api.DeleteDevice(context.WithoutCancel(ctx), deviceToken, deviceId)
 
-	return nil
-}
-
-func saveDeviceToken(deviceToken string) error {
-	pathSecguroConfigDir, err := getSecguroConfigDirPath()
-	if err != nil {
 		return err
 	}
 
-	err = ensureDirectoryExists(pathSecguroConfigDir)
+	err = credentials.Save(credentials.Credentials{
+		DeviceId:    deviceId,
+		DeviceName:  deviceName,
+		DeviceToken: deviceToken,
+	})
 	if err != nil {
 		return err
 	}
 
-	const filePermissions = 0600
-	err = os.WriteFile(pathSecguroConfigDir+"/"+deviceTokenFileName, []byte(deviceToken), filePermissions)
+	// Remember the backend so that later scans report to the server the token belongs to.
+	err = profile.WriteServerUrls(profile.ServerUrls{
+		ServerUrl: config.ServerUrl,
+		WebappUrl: config.WebappUrl,
+	})
 	if err != nil {
 		return err
 	}
 
+	fmt.Println("Device registration successful. Future scans will be visible in the secguro webapp.")
+
 	return nil
 }
 
-func acquireDeviceIdAndDeviceToken() (uint, string, error) {
-	deviceName, err := os.Hostname()
-	if err != nil {
-		return 0, "", err
-	}
-
-	urlEndpointPostDevice := config.ServerUrl + "/" + endpointPostDevice
-
+func acquireDeviceIdAndDeviceToken(ctx context.Context, deviceName string) (uint, string, error) {
 	devicePostReq := types.DevicePostReq{
 		DeviceName: deviceName,
 	}
 
-	result := types.DevicePostRes{} //nolint: exhaustruct
-	client := resty.New()
-	response, err := client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(devicePostReq).
-		SetResult(&result).
-		Post(urlEndpointPostDevice)
-
+	result, err := api.PostDevice(ctx, devicePostReq)
 	if err != nil {
 		return 0, "", err
 	}
 
-	if response.StatusCode() != http.StatusCreated {
-		return 0, "", errors.New("received bad status code")
-	}
-
 	return result.ID, result.DeviceToken, nil
 }
 
-func isDeviceRegistered(deviceId uint) (bool, error) {
-	urlEndpointGetDeviceRegistration := strings.Replace(
-		config.ServerUrl+"/"+endpointGetDeviceRegistration,
-		"{deviceId}", fmt.Sprintf("%d", deviceId), 1)
-
-	result := types.DeviceRegistrationRes{} //nolint: exhaustruct
-	client := resty.New()
-	response, err := client.R().
-		SetHeader("Content-Type", "application/json").
-		SetResult(&result).
-		Get(urlEndpointGetDeviceRegistration)
-
+func isDeviceRegistered(ctx context.Context, deviceId uint) (bool, error) {
+	result, err := api.GetDeviceRegistration(ctx, deviceId)
 	if err != nil {
 		return false, err
 	}
 
-	if response.StatusCode() != http.StatusOK {
-		return false, errors.New("received bad status code")
-	}
-
 	return result.IsRegistered, nil
 }
 
@@ -136,42 +103,42 @@ func GetAuthToken() (string, error) {
 }
 
 func getDeviceToken() (string, error) {
-	pathSecguroConfigDir, err := getSecguroConfigDirPath()
+	storedCredentials, err := credentials.Load()
 	if err != nil {
 		return "", err
 	}
 
-	deviceTokenFilePath := pathSecguroConfigDir + "/" + deviceTokenFileName
+	if storedCredentials == nil {
+		return "", nil
+	}
 
-	doesFileExist, err := utils.DoesFileExist(deviceTokenFilePath)
-	if err != nil {
-		return "", errors.New("cannot determine whether user is logged in")
+	return storedCredentials.DeviceToken, nil
+}
+
+/**
+ * Device tokens are only sent to the server that has issued them, which is stored in the
+ * profile on login. Returns an error if config.ServerUrl points to another server while the
+ * device token would be used. The CI token takes precedence over the device token.
+ * Profiles without stored server have been logged in to the default server.
+ */
+func CheckServerUrl(storedServerUrl string) error {
+	if storedServerUrl == "" {
+		storedServerUrl = config.DefaultServerUrl
 	}
 
-	if !doesFileExist {
-		return "", nil
+	if storedServerUrl == config.ServerUrl || os.Getenv(config.CiTokenEnvVarName) != "" {
+		return nil
 	}
 
-	authTokenBytes, err := os.ReadFile(deviceTokenFilePath)
+	deviceToken, err := getDeviceToken()
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	authToken := string(authTokenBytes)
-
-	return authToken, nil
-}
-
-func ensureDirectoryExists(path string) error {
-	const directoryPermissions = 0700
-	return os.MkdirAll(path, directoryPermissions)
-}
-
-func getSecguroConfigDirPath() (string, error) {
-	pathHomeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+	if deviceToken == "" {
+		return nil
 	}
 
-	return pathHomeDir + "/" + secguroConfigDirName, nil
+	return fmt.Errorf("profile %s is logged in to %s, so its device token must not be sent to %s; "+
+		"use another profile (--profile) for that server", profile.GetCurrent(), storedServerUrl, config.ServerUrl)
 }
@@ -0,0 +1,43 @@
+package login
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/secguro/secguro-cli/pkg/api"
+	"github.com/secguro/secguro-cli/pkg/credentials"
+)
+
+func CommandLogout(ctx context.Context) error {
+	storedCredentials, err := credentials.Load()
+	if err != nil {
+		return err
+	}
+
+	if storedCredentials == nil {
+		fmt.Println("Not logged in.")
+
+		return nil
+	}
+
+	// Credentials migrated from the plaintext file do not carry the device ID.
+	if storedCredentials.DeviceId == 0 {
+		fmt.Println("Cannot revoke device token because the device ID is unknown. " +
+			"Please remove the device in the secguro webapp.")
+	} else {
+		err = api.DeleteDevice(ctx, storedCredentials.DeviceToken, storedCredentials.DeviceId)
+		if err != nil {
+			fmt.Println("Failed to revoke device token (" + err.Error() + "). " +
+				"Please remove the device in the secguro webapp.")
+		}
+	}
+
+	err = credentials.Delete()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Logged out.")
+
+	return nil
+}
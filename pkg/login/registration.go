@@ -0,0 +1,188 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/logging"
+	"golang.org/x/term"
+)
+
+var errLoginCancelled = errors.New("login cancelled")
+
+/**
+ * Polls the server until the device has been registered or the expiry has been
+ * reached. Transient errors are retried with exponential backoff. Shows a spinner
+ * with the remaining time if stdout is a terminal.
+ */
+func waitForRegistration(ctx context.Context, deviceId uint, expiry time.Duration) error {
+	deadline := time.Now().Add(expiry)
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	resultChan := make(chan error, 1)
+	go func() {
+		resultChan <- pollRegistration(ctx, deviceId)
+	}()
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		logging.Info("Waiting for registration...")
+
+		return <-resultChan
+	}
+
+	return waitWithSpinner(cancel, deadline, resultChan)
+}
+
+func pollRegistration(ctx context.Context, deviceId uint) error {
+	const pollingInterval = config.DeviceRegistrationPollingFrequencyInMs * time.Millisecond
+	const maxPollingInterval = config.DeviceRegistrationMaxPollingIntervalInMs * time.Millisecond
+
+	interval := pollingInterval
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			return getRegistrationAbortError(ctx, lastErr)
+		case <-time.After(interval):
+		}
+
+		isRegistered, err := isDeviceRegistered(ctx, deviceId)
+		if ctx.Err() != nil {
+			return getRegistrationAbortError(ctx, lastErr)
+		}
+		if err != nil {
+			lastErr = err
+			interval = min(2*interval, maxPollingInterval) //nolint: mnd
+
+			continue
+		}
+
+		lastErr = nil
+		interval = pollingInterval
+
+		if isRegistered {
+			return nil
+		}
+	}
+}
+
+func getRegistrationAbortError(ctx context.Context, lastErr error) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errLoginCancelled
+	}
+
+	if lastErr != nil {
+		return fmt.Errorf("registration link expired (last error: %w)", lastErr)
+	}
+
+	return errors.New("registration link expired; please run login again")
+}
+
+type registrationResultMsg struct {
+	err error
+}
+
+type modelRegistrationSpinner struct {
+	spinner    spinner.Model
+	deadline   time.Time
+	isFinished bool
+	err        error
+}
+
+// resultChan is only read here: the result is passed on both to the program (to stop the
+// spinner) and to doneChan (which is read even if the program has already quit).
+func waitWithSpinner(cancel context.CancelFunc, deadline time.Time, resultChan chan error) error {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+
+	p := tea.NewProgram(modelRegistrationSpinner{
+		spinner:    s,
+		deadline:   deadline,
+		isFinished: false,
+		err:        nil,
+	})
+
+	doneChan := make(chan error, 1)
+	go func() {
+		err := <-resultChan
+		doneChan <- err
+		// Does not block if the program has quit already.
+		p.Send(registrationResultMsg{err: err})
+	}()
+
+	m, err := p.Run()
+	if err != nil {
+		cancel()
+		<-doneChan
+
+		return err
+	}
+
+	// The spinner also quits if the user pressed Ctrl-C.
+	if m, ok := m.(modelRegistrationSpinner); ok && m.isFinished {
+		return m.err
+	}
+
+	cancel()
+
+	return <-doneChan
+}
+
+func (m modelRegistrationSpinner) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m modelRegistrationSpinner) Update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint: ireturn // must be like this
+	switch msg := msg.(type) {
+	case registrationResultMsg:
+		m.isFinished = true
+		m.err = msg.err
+
+		return m, tea.Quit
+	case tea.KeyMsg: //nolint: exhaustive
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		}
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m modelRegistrationSpinner) View() string {
+	if m.isFinished {
+		return ""
+	}
+
+	remaining := max(time.Until(m.deadline), 0).Round(time.Second)
+
+	return fmt.Sprintf("%s Waiting for registration (%v remaining, esc to cancel)\n", m.spinner.View(), remaining)
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	return cmd.Start()
+}
@@ -0,0 +1,52 @@
+package login
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/credentials"
+	"github.com/secguro/secguro-cli/pkg/profile"
+)
+
+func CommandWhoami() error {
+	fmt.Println("profile: " + profile.GetCurrent())
+	fmt.Println("server: " + config.ServerUrl)
+	fmt.Println("webapp: " + config.WebappUrl)
+
+	if os.Getenv(config.CiTokenEnvVarName) != "" {
+		fmt.Println("Using CI token from " + config.CiTokenEnvVarName + ".")
+
+		return nil
+	}
+
+	store, err := credentials.GetStore()
+	if err != nil {
+		return err
+	}
+
+	storedCredentials, err := credentials.Load()
+	if err != nil {
+		return err
+	}
+
+	if storedCredentials == nil {
+		fmt.Println("Not logged in.")
+
+		return nil
+	}
+
+	deviceName := storedCredentials.DeviceName
+	if deviceName == "" {
+		deviceName = "unknown"
+	}
+	deviceId := "unknown"
+	if storedCredentials.DeviceId != 0 {
+		deviceId = fmt.Sprintf("%d", storedCredentials.DeviceId)
+	}
+
+	fmt.Println("device: " + deviceName + " (ID " + deviceId + ")")
+	fmt.Println("credential store: " + store.Name())
+
+	return nil
+}
@@ -3,24 +3,30 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/secguro/secguro-cli/pkg/functional"
 	"github.com/secguro/secguro-cli/pkg/types"
 )
 
 type UnifiedFindingSansGitInfo struct {
-	Detector    string
-	Rule        string
-	File        string
-	LineStart   int
-	LineEnd     int
-	ColumnStart int
-	ColumnEnd   int
-	Match       string
-	Hint        string
+	Detector        string
+	Rule            string
+	File            string
+	LineStart       int
+	LineEnd         int
+	ColumnStart     int
+	ColumnEnd       int
+	Match           string
+	Hint            string
+	Severity        string
+	Fingerprint     string
+	OtherDetections []types.Detection
 }
 
 func PrintJson(unifiedFindings []types.UnifiedFinding, gitMode bool) (string, error) {
+	unifiedFindings = withNormalizedSeverities(unifiedFindings)
+
 	if gitMode {
 		return printJsonInternal(unifiedFindings)
 	} else {
@@ -36,6 +42,9 @@ func PrintJson(unifiedFindings []types.UnifiedFinding, gitMode bool) (string, er
 					unifiedFinding.ColumnEnd,
 					unifiedFinding.Match,
 					unifiedFinding.Hint,
+					unifiedFinding.Severity,
+					unifiedFinding.Fingerprint,
+					unifiedFinding.OtherDetections,
 				}
 			})
 
@@ -43,6 +52,16 @@ func PrintJson(unifiedFindings []types.UnifiedFinding, gitMode bool) (string, er
 	}
 }
 
+// Local output uses the normalized severity names whereas findings and reports keep the
+// severity names as the detectors report them (e.g. semgrep's ERROR/WARNING/INFO).
+func withNormalizedSeverities(unifiedFindings []types.UnifiedFinding) []types.UnifiedFinding {
+	return functional.Map(unifiedFindings, func(unifiedFinding types.UnifiedFinding) types.UnifiedFinding {
+		unifiedFinding.Severity = types.NormalizeSeverity(unifiedFinding.Severity).String()
+
+		return unifiedFinding
+	})
+}
+
 func printJsonInternal[T types.UnifiedFinding | UnifiedFindingSansGitInfo](unifiedFindings []T) (string, error) {
 	// Handle case of un-initialzed array (would cause
 	// conversion to "null" instead of "[]").
@@ -81,6 +100,10 @@ func GetFindingBody(gitMode bool, unifiedFinding types.UnifiedFinding) string {
 	result := ""
 	result += fmt.Sprintf("  detector: %v\n", unifiedFinding.Detector)
 	result += fmt.Sprintf("  rule: %v\n", unifiedFinding.Rule)
+	for _, detection := range unifiedFinding.OtherDetections {
+		result += fmt.Sprintf("  also detected by: %v (rule: %v)\n", detection.Detector, detection.Rule)
+	}
+	result += fmt.Sprintf("  severity: %v\n", types.NormalizeSeverity(unifiedFinding.Severity))
 	result += fmt.Sprintf("  match: %v\n", unifiedFinding.Match)
 	result += "  location: " +
 		getLocation(unifiedFinding.File, unifiedFinding.LineStart, unifiedFinding.ColumnStart)
@@ -89,7 +112,7 @@ func GetFindingBody(gitMode bool, unifiedFinding types.UnifiedFinding) string {
 			getLocation(unifiedFinding.GitInfo.File, unifiedFinding.GitInfo.Line, unifiedFinding.ColumnStart)
 	}
 	if len(unifiedFinding.Hint) > 0 {
-		result += fmt.Sprintf("  hint: %v\n", unifiedFinding.Hint)
+		result += fmt.Sprintf("  hint: %v\n", strings.ReplaceAll(unifiedFinding.Hint, "\n", "\n    "))
 	}
 	if gitMode && unifiedFinding.GitInfo != nil {
 		result += fmt.Sprintf("  commit hash: %v\n", unifiedFinding.GitInfo.CommitHash)
@@ -98,10 +121,25 @@ func GetFindingBody(gitMode bool, unifiedFinding types.UnifiedFinding) string {
 		result += fmt.Sprintf("  author email address: %v\n", unifiedFinding.GitInfo.AuthorEmailAddress)
 		result += fmt.Sprintf("  commit summary: %v\n", unifiedFinding.GitInfo.CommitSummary)
 	}
+	if gitMode && len(unifiedFinding.Occurrences) > 0 {
+		result += fmt.Sprintf("  further occurrences: %d\n", len(unifiedFinding.Occurrences))
+		for _, occurrence := range unifiedFinding.Occurrences {
+			result += "    • " + getOccurrenceDescription(occurrence)
+		}
+	}
 
 	return result
 }
 
+func getOccurrenceDescription(occurrence types.Occurrence) string {
+	if occurrence.GitInfo == nil {
+		return getLocation(occurrence.File, occurrence.LineStart, occurrence.ColumnStart)
+	}
+
+	return fmt.Sprintf("commit %v (%v): ", occurrence.GitInfo.CommitHash, occurrence.GitInfo.CommitDate) +
+		getLocation(occurrence.GitInfo.File, occurrence.GitInfo.Line, occurrence.ColumnStart)
+}
+
 func getLocation(path string, line int, column int) string {
 	if path == "" {
 		return "\033[3m(does not exist)\033[0m\n"
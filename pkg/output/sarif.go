@@ -0,0 +1,258 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+const sarifVersion = "2.1.0"
+
+// Key of the fingerprint in partialFingerprints; versioned in case the computation changes.
+const sarifFingerprintKey = "secguroFingerprint/v1"
+
+// Base ID for artifact locations; resolved by SARIF consumers to the root of the scanned directory.
+const sarifUriBaseId = "%SRCROOT%"
+
+var detectorInformationUris = map[string]string{
+	"gitleaks":        "https://github.com/gitleaks/gitleaks",
+	"semgrep":         "https://semgrep.dev",
+	"dependencycheck": "https://owasp.org/www-project-dependency-check/",
+}
+
+type SarifLog struct {
+	Schema  string     `json:"$schema"` //nolint: tagliatelle
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string                `json:"name"`
+	InformationUri string                `json:"informationUri,omitempty"`
+	Rules          []SarifRuleDescriptor `json:"rules"`
+}
+
+type SarifRuleDescriptor struct {
+	Id               string       `json:"id"`
+	ShortDescription SarifMessage `json:"shortDescription"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleId              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             SarifMessage      `json:"message"`
+	Locations           []SarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           *SarifRegion          `json:"region,omitempty"`
+}
+
+type SarifArtifactLocation struct {
+	Uri       string `json:"uri"`
+	UriBaseId string `json:"uriBaseId"`
+}
+
+type SarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+func PrintSarif(unifiedFindings []types.UnifiedFinding, gitMode bool) (string, error) {
+	sarifLog := SarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    getSarifRuns(unifiedFindings, gitMode),
+	}
+
+	resultJson, err := json.Marshal(sarifLog)
+	if err != nil {
+		return "error", err
+	}
+
+	return string(resultJson), nil
+}
+
+// Creates one run per detector in order of the first finding of each detector.
+func getSarifRuns(unifiedFindings []types.UnifiedFinding, gitMode bool) []SarifRun {
+	runs := make([]SarifRun, 0)
+	runIndicesByDetector := make(map[string]int)
+	ruleIndicesByDetector := make(map[string]map[string]int)
+
+	for _, unifiedFinding := range unifiedFindings {
+		runIndex, ok := runIndicesByDetector[unifiedFinding.Detector]
+		if !ok {
+			runs = append(runs, SarifRun{
+				Tool: SarifTool{
+					Driver: SarifDriver{
+						Name:           unifiedFinding.Detector,
+						InformationUri: detectorInformationUris[unifiedFinding.Detector],
+						Rules:          make([]SarifRuleDescriptor, 0),
+					},
+				},
+				Results: make([]SarifResult, 0),
+			})
+			runIndex = len(runs) - 1
+			runIndicesByDetector[unifiedFinding.Detector] = runIndex
+			ruleIndicesByDetector[unifiedFinding.Detector] = make(map[string]int)
+		}
+
+		run := &runs[runIndex]
+		ruleIndices := ruleIndicesByDetector[unifiedFinding.Detector]
+
+		ruleIndex, ok := ruleIndices[unifiedFinding.Rule]
+		if !ok {
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, SarifRuleDescriptor{
+				Id:               unifiedFinding.Rule,
+				ShortDescription: SarifMessage{Text: unifiedFinding.Rule},
+			})
+			ruleIndex = len(run.Tool.Driver.Rules) - 1
+			ruleIndices[unifiedFinding.Rule] = ruleIndex
+		}
+
+		run.Results = append(run.Results, getSarifResult(unifiedFinding, ruleIndex, gitMode))
+	}
+
+	return runs
+}
+
+func getSarifResult(unifiedFinding types.UnifiedFinding, ruleIndex int, gitMode bool) SarifResult {
+	messageText := unifiedFinding.Hint
+	if messageText == "" {
+		messageText = "Finding of rule " + unifiedFinding.Rule
+	}
+
+	locations := make([]SarifLocation, 0)
+	// Findings only present in the git history do not have a location in the working directory.
+	if unifiedFinding.File != "" {
+		locations = append(locations, SarifLocation{
+			PhysicalLocation: SarifPhysicalLocation{
+				ArtifactLocation: SarifArtifactLocation{
+					Uri:       strings.TrimPrefix(unifiedFinding.File, "/"),
+					UriBaseId: sarifUriBaseId,
+				},
+				Region: getSarifRegion(unifiedFinding),
+			},
+		})
+	}
+
+	var properties map[string]any
+	if gitMode && unifiedFinding.GitInfo != nil {
+		properties = map[string]any{
+			"commitHash":         unifiedFinding.GitInfo.CommitHash,
+			"commitDate":         unifiedFinding.GitInfo.CommitDate,
+			"authorName":         unifiedFinding.GitInfo.AuthorName,
+			"authorEmailAddress": unifiedFinding.GitInfo.AuthorEmailAddress,
+			"commitSummary":      unifiedFinding.GitInfo.CommitSummary,
+			"historicalFile":     unifiedFinding.GitInfo.File,
+			"historicalLine":     unifiedFinding.GitInfo.Line,
+		}
+	}
+
+	if len(unifiedFinding.OtherDetections) > 0 || len(unifiedFinding.Occurrences) > 0 {
+		if properties == nil {
+			properties = make(map[string]any)
+		}
+
+		if len(unifiedFinding.OtherDetections) > 0 {
+			properties["otherDetections"] = getSarifOtherDetections(unifiedFinding.OtherDetections)
+		}
+
+		if len(unifiedFinding.Occurrences) > 0 {
+			properties["historicalOccurrences"] = getSarifOccurrences(unifiedFinding.Occurrences)
+		}
+	}
+
+	var partialFingerprints map[string]string
+	if unifiedFinding.Fingerprint != "" {
+		partialFingerprints = map[string]string{sarifFingerprintKey: unifiedFinding.Fingerprint}
+	}
+
+	return SarifResult{
+		RuleId:              unifiedFinding.Rule,
+		RuleIndex:           ruleIndex,
+		Level:               getSarifLevel(unifiedFinding.Severity),
+		Message:             SarifMessage{Text: messageText},
+		Locations:           locations,
+		PartialFingerprints: partialFingerprints,
+		Properties:          properties,
+	}
+}
+
+func getSarifOtherDetections(detections []types.Detection) []map[string]string {
+	return functional.Map(detections, func(detection types.Detection) map[string]string {
+		return map[string]string{"detector": detection.Detector, "rule": detection.Rule}
+	})
+}
+
+func getSarifOccurrences(occurrences []types.Occurrence) []map[string]any {
+	return functional.Map(occurrences, func(occurrence types.Occurrence) map[string]any {
+		sarifOccurrence := make(map[string]any)
+		if occurrence.File != "" {
+			sarifOccurrence["file"] = strings.TrimPrefix(occurrence.File, "/")
+			sarifOccurrence["line"] = occurrence.LineStart
+		}
+
+		if occurrence.GitInfo != nil {
+			sarifOccurrence["commitHash"] = occurrence.GitInfo.CommitHash
+			sarifOccurrence["commitDate"] = occurrence.GitInfo.CommitDate
+			sarifOccurrence["historicalFile"] = occurrence.GitInfo.File
+			sarifOccurrence["historicalLine"] = occurrence.GitInfo.Line
+		}
+
+		return sarifOccurrence
+	})
+}
+
+// Returns nil for findings without line information (e.g. dependencycheck findings).
+func getSarifRegion(unifiedFinding types.UnifiedFinding) *SarifRegion {
+	if unifiedFinding.LineStart < 1 {
+		return nil
+	}
+
+	return &SarifRegion{
+		StartLine:   unifiedFinding.LineStart,
+		StartColumn: max(unifiedFinding.ColumnStart, 0),
+		EndLine:     max(unifiedFinding.LineEnd, 0),
+		EndColumn:   max(unifiedFinding.ColumnEnd, 0),
+	}
+}
+
+func getSarifLevel(severity string) string {
+	switch types.NormalizeSeverity(severity) {
+	case types.SeverityCritical, types.SeverityHigh:
+		return "error"
+	case types.SeverityMedium:
+		return "warning"
+	case types.SeverityLow, types.SeverityInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
@@ -0,0 +1,208 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/secguro/secguro-cli/pkg/functional"
+	"github.com/secguro/secguro-cli/pkg/utils"
+)
+
+const DefaultProfileName = "default"
+
+const secguroConfigDirName = ".secguro"
+const profilesDirName = "profiles"
+const serverUrlsFileName = "server.json"
+
+var profileNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var currentProfileName = DefaultProfileName
+
+// Base URLs of the secguro backend that a profile belongs to.
+type ServerUrls struct {
+	ServerUrl string
+	WebappUrl string
+}
+
+func SetCurrent(profileName string) error {
+	if !profileNameRegex.MatchString(profileName) {
+		return errors.New("invalid profile name (allowed characters: A-Z, a-z, 0-9, _ and -): " + profileName)
+	}
+
+	currentProfileName = profileName
+
+	return nil
+}
+
+func GetCurrent() string {
+	return currentProfileName
+}
+
+func GetSecguroConfigDirPath() (string, error) {
+	pathHomeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(pathHomeDir, secguroConfigDirName), nil
+}
+
+/**
+ * The default profile lives directly in ~/.secguro so that logins from before the
+ * introduction of profiles remain valid. Other profiles live in ~/.secguro/profiles/<name>.
+ */
+func GetDirPath() (string, error) {
+	return getDirPathOfProfile(currentProfileName)
+}
+
+func getDirPathOfProfile(profileName string) (string, error) {
+	pathSecguroConfigDir, err := GetSecguroConfigDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	if profileName == DefaultProfileName {
+		return pathSecguroConfigDir, nil
+	}
+
+	return filepath.Join(pathSecguroConfigDir, profilesDirName, profileName), nil
+}
+
+func EnsureDirExists() (string, error) {
+	pathProfileDir, err := GetDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	const directoryPermissions = 0700
+	err = os.MkdirAll(pathProfileDir, directoryPermissions)
+
+	return pathProfileDir, err
+}
+
+func WriteServerUrls(serverUrls ServerUrls) error {
+	pathProfileDir, err := EnsureDirExists()
+	if err != nil {
+		return err
+	}
+
+	serverUrlsJson, err := json.MarshalIndent(serverUrls, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	const filePermissions = 0600
+
+	return os.WriteFile(filepath.Join(pathProfileDir, serverUrlsFileName), serverUrlsJson, filePermissions)
+}
+
+// Returns empty URLs if none have been stored for the current profile.
+func ReadServerUrls() (ServerUrls, error) {
+	pathProfileDir, err := GetDirPath()
+	if err != nil {
+		return ServerUrls{}, err //nolint: exhaustruct
+	}
+
+	return readServerUrlsFromDir(pathProfileDir)
+}
+
+func readServerUrlsFromDir(pathProfileDir string) (ServerUrls, error) {
+	serverUrls := ServerUrls{} //nolint: exhaustruct
+
+	serverUrlsFilePath := filepath.Join(pathProfileDir, serverUrlsFileName)
+	doesFileExist, err := utils.DoesFileExist(serverUrlsFilePath)
+	if err != nil {
+		return serverUrls, err
+	}
+
+	if !doesFileExist {
+		return serverUrls, nil
+	}
+
+	serverUrlsJson, err := os.ReadFile(serverUrlsFilePath)
+	if err != nil {
+		return serverUrls, err
+	}
+
+	err = json.Unmarshal(serverUrlsJson, &serverUrls)
+
+	return serverUrls, err
+}
+
+type ProfileInfo struct {
+	Name       string
+	ServerUrls ServerUrls
+}
+
+// Lists the default profile followed by all named profiles.
+func List() ([]ProfileInfo, error) {
+	profileNames := []string{DefaultProfileName}
+
+	pathSecguroConfigDir, err := GetSecguroConfigDirPath()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(pathSecguroConfigDir, profilesDirName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && profileNameRegex.MatchString(entry.Name()) {
+			profileNames = append(profileNames, entry.Name())
+		}
+	}
+
+	// The current profile is listed even if nothing has been stored for it yet.
+	if !functional.ArrayIncludes(profileNames, currentProfileName) {
+		profileNames = append(profileNames, currentProfileName)
+	}
+
+	profileInfos := make([]ProfileInfo, 0, len(profileNames))
+	for _, profileName := range profileNames {
+		pathProfileDir, err := getDirPathOfProfile(profileName)
+		if err != nil {
+			return nil, err
+		}
+
+		serverUrls, err := readServerUrlsFromDir(pathProfileDir)
+		if err != nil {
+			return nil, err
+		}
+
+		profileInfos = append(profileInfos, ProfileInfo{
+			Name:       profileName,
+			ServerUrls: serverUrls,
+		})
+	}
+
+	return profileInfos, nil
+}
+
+func CommandList() error {
+	profileInfos, err := List()
+	if err != nil {
+		return err
+	}
+
+	for _, profileInfo := range profileInfos {
+		marker := " "
+		if profileInfo.Name == currentProfileName {
+			marker = "*"
+		}
+
+		serverUrl := profileInfo.ServerUrls.ServerUrl
+		if serverUrl == "" {
+			serverUrl = "(not logged in)"
+		}
+
+		fmt.Println(marker + " " + profileInfo.Name + ": " + serverUrl)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package redaction
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+// Number of characters kept at the start and at the end of a redacted value.
+const visibleCharacterCount = 4
+
+// Values shorter than this are masked completely.
+const minLengthForVisibleCharacters = 4 * visibleCharacterCount
+
+// Number of hex characters of the hash that is included for correlating findings.
+const hashLength = 12
+
+var redactedValueRegex = regexp.MustCompile(`^.{0,4}\*+.{0,4} \(sha256:[0-9a-f]{12}\)$`)
+
+const secretDetectorName = "gitleaks"
+
+// Rule IDs of the semgrep secret rules contain this segment (e.g. generic.secrets.security...).
+const semgrepSecretRuleSegment = ".secrets."
+
+// Broader than the rules that are fixed as secrets, as anything that may be a secret is redacted.
+func IsSecretFinding(unifiedFinding types.UnifiedFinding) bool {
+	return unifiedFinding.Detector == secretDetectorName ||
+		strings.Contains(unifiedFinding.Rule, semgrepSecretRuleSegment)
+}
+
+// Returns copies of the findings in which the matches of secret findings are redacted.
+func RedactFindings(unifiedFindings []types.UnifiedFinding) []types.UnifiedFinding {
+	redactedFindings := make([]types.UnifiedFinding, 0, len(unifiedFindings))
+	for _, unifiedFinding := range unifiedFindings {
+		if IsSecretFinding(unifiedFinding) {
+			unifiedFinding.Match = Redact(unifiedFinding.Match)
+		}
+
+		redactedFindings = append(redactedFindings, unifiedFinding)
+	}
+
+	return redactedFindings
+}
+
+/**
+ * Masks all but the first and last few characters and appends a hash of the value
+ * so that findings of the same secret can be correlated, e.g.
+ * "ghp_****************************Xb9Q (sha256:3f1c0a5d2e7b)". Values that have
+ * been redacted already are returned as they are.
+ */
+func Redact(value string) string {
+	if value == "" || redactedValueRegex.MatchString(value) {
+		return value
+	}
+
+	hash := sha256.Sum256([]byte(value))
+	hashSuffix := " (sha256:" + hex.EncodeToString(hash[:])[:hashLength] + ")"
+
+	characters := []rune(value)
+	if len(characters) < minLengthForVisibleCharacters {
+		return strings.Repeat("*", len(characters)) + hashSuffix
+	}
+
+	return string(characters[:visibleCharacterCount]) +
+		strings.Repeat("*", len(characters)-2*visibleCharacterCount) +
+		string(characters[len(characters)-visibleCharacterCount:]) + hashSuffix
+}
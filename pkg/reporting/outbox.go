@@ -0,0 +1,246 @@
+package reporting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/secguro/secguro-cli/pkg/logging"
+	"github.com/secguro/secguro-cli/pkg/login"
+	"github.com/secguro/secguro-cli/pkg/profile"
+	"github.com/secguro/secguro-cli/pkg/redaction"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+// Located in the profile directory so that reports are sent to the server they were meant for.
+const outboxDirName = "outbox"
+const queuedReportFileExtension = ".json"
+
+// Reports rejected by the server are kept for inspection but not sent again.
+const rejectedReportFileExtension = ".rejected"
+
+// Stores the UploadProgress of a queued report whose upload in batches failed.
+const uploadProgressFileExtension = ".progress"
+
+func CommandReportFlush(ctx context.Context) error {
+	authToken, err := login.GetAuthToken()
+	if err != nil {
+		return err
+	}
+
+	if authToken == "" {
+		return errors.New("not logged in; please run secguro login first")
+	}
+
+	sentCount, remainingCount, err := flushOutbox(ctx, authToken)
+	logging.Info(fmt.Sprintf("Sent %d queued scan reports; %d remaining", sentCount, remainingCount))
+	if err != nil {
+		return fmt.Errorf("failed to send queued scan reports: %w", err)
+	}
+
+	return nil
+}
+
+func getOutboxDirPath() (string, error) {
+	pathProfileDir, err := profile.GetDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(pathProfileDir, outboxDirName), nil
+}
+
+/**
+ * Queued reports are kept until they have been sent (or, if rejected by the server, until they
+ * are deleted by hand). As they may be kept for long, secrets are always redacted in them (even
+ * with --show-secrets), and they are only readable by the user.
+ */
+func enqueueReport(scanPostReq types.ScanPostReq, progress UploadProgress, uploadErr error) error {
+	scanPostReq.Findings = redaction.RedactFindings(scanPostReq.Findings)
+
+	outboxDirPath, err := getOutboxDirPath()
+	if err != nil {
+		return err
+	}
+
+	const directoryPermissions = 0700
+	err = os.MkdirAll(outboxDirPath, directoryPermissions)
+	if err != nil {
+		return err
+	}
+
+	scanPostReqJson, err := json.Marshal(scanPostReq)
+	if err != nil {
+		return err
+	}
+
+	// File names sort in the order in which the reports have been queued.
+	queuedReportFilePath := filepath.Join(outboxDirPath,
+		fmt.Sprintf("%d%s", time.Now().UnixNano(), queuedReportFileExtension))
+
+	const filePermissions = 0600
+	err = os.WriteFile(queuedReportFilePath, scanPostReqJson, filePermissions)
+	if err != nil {
+		return err
+	}
+
+	err = writeUploadProgress(queuedReportFilePath, progress)
+	if err != nil {
+		return err
+	}
+
+	logging.Warn("Failed to send scan report (" + uploadErr.Error() + "). It has been queued and will be " +
+		"sent with the next scan or by running: secguro report flush")
+
+	return nil
+}
+
+/**
+ * Sends queued reports from oldest to newest. Stops at the first report that cannot
+ * be sent so that the order is kept.
+ */
+func flushOutbox(ctx context.Context, authToken string) (int, int, error) {
+	queuedReportFilePaths, err := getQueuedReportFilePaths()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sentCount := 0
+	for index, queuedReportFilePath := range queuedReportFilePaths {
+		logging.Progress("Sending queued scan report " + filepath.Base(queuedReportFilePath))
+		err := sendQueuedReport(ctx, authToken, queuedReportFilePath)
+		if err != nil {
+			logging.ProgressDone("failed")
+		} else {
+			logging.ProgressDone("done")
+		}
+		if errors.Is(err, errReportRejected) {
+			logging.Warn("Server rejected queued scan report " + queuedReportFilePath + "; it will not be sent again.")
+
+			err = os.Rename(queuedReportFilePath,
+				strings.TrimSuffix(queuedReportFilePath, queuedReportFileExtension)+rejectedReportFileExtension)
+			if err == nil {
+				err = removeUploadProgress(queuedReportFilePath)
+			}
+			if err != nil {
+				return sentCount, len(queuedReportFilePaths) - index, err
+			}
+
+			continue
+		}
+		if err != nil {
+			return sentCount, len(queuedReportFilePaths) - index, err
+		}
+
+		sentCount++
+	}
+
+	return sentCount, 0, nil
+}
+
+func getQueuedReportFilePaths() ([]string, error) {
+	outboxDirPath, err := getOutboxDirPath()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(outboxDirPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return make([]string, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	queuedReportFilePaths := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), queuedReportFileExtension) {
+			queuedReportFilePaths = append(queuedReportFilePaths, filepath.Join(outboxDirPath, entry.Name()))
+		}
+	}
+
+	slices.Sort(queuedReportFilePaths)
+
+	return queuedReportFilePaths, nil
+}
+
+func sendQueuedReport(ctx context.Context, authToken string, queuedReportFilePath string) error {
+	scanPostReqJson, err := os.ReadFile(queuedReportFilePath)
+	if err != nil {
+		return err
+	}
+
+	var scanPostReq types.ScanPostReq
+	err = json.Unmarshal(scanPostReqJson, &scanPostReq)
+	if err != nil {
+		return err
+	}
+
+	progress, err := readUploadProgress(queuedReportFilePath)
+	if err != nil {
+		return err
+	}
+
+	err = uploadScan(ctx, authToken, scanPostReq, &progress)
+	if err != nil {
+		return errors.Join(err, writeUploadProgress(queuedReportFilePath, progress))
+	}
+
+	err = os.Remove(queuedReportFilePath)
+	if err != nil {
+		return err
+	}
+
+	return removeUploadProgress(queuedReportFilePath)
+}
+
+func getUploadProgressFilePath(queuedReportFilePath string) string {
+	return strings.TrimSuffix(queuedReportFilePath, queuedReportFileExtension) + uploadProgressFileExtension
+}
+
+// Returns an empty progress if the upload has not been started.
+func readUploadProgress(queuedReportFilePath string) (UploadProgress, error) {
+	progress := UploadProgress{UploadId: 0, UploadedFindingCount: 0}
+
+	progressJson, err := os.ReadFile(getUploadProgressFilePath(queuedReportFilePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return progress, err
+	}
+
+	err = json.Unmarshal(progressJson, &progress)
+
+	return progress, err
+}
+
+func writeUploadProgress(queuedReportFilePath string, progress UploadProgress) error {
+	if progress.UploadId == 0 {
+		return nil
+	}
+
+	progressJson, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	const filePermissions = 0600
+
+	return os.WriteFile(getUploadProgressFilePath(queuedReportFilePath), progressJson, filePermissions)
+}
+
+func removeUploadProgress(queuedReportFilePath string) error {
+	err := os.Remove(getUploadProgressFilePath(queuedReportFilePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
@@ -1,71 +1,53 @@
 package reporting
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 
-	resty "github.com/go-resty/resty/v2"
+	"github.com/secguro/secguro-cli/pkg/api"
 	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/functional"
 	"github.com/secguro/secguro-cli/pkg/git"
+	"github.com/secguro/secguro-cli/pkg/logging"
 	"github.com/secguro/secguro-cli/pkg/login"
 	"github.com/secguro/secguro-cli/pkg/types"
 )
 
-const endpointPostScan = "scans"
+var errReportRejected = errors.New("server rejected scan report")
 
-func ReportScan(authToken string, assetName string, assetRemoteUrls []string,
-	branch string, revision string, unifiedFindings []types.UnifiedFinding, failedDetectors []string) error {
-	fmt.Print("Sending scan report to server...")
-
-	authProvider := "secguro"
-
-	urlEndpointPostScan := config.ServerUrl + "/" + endpointPostScan
-
-	scanPostReq := types.ScanPostReq{
-		AssetName:       assetName,
-		AssetRemoteUrls: assetRemoteUrls,
-		Branch:          branch,
-		Revision:        revision,
-		Findings:        unifiedFindings,
-		FailedDetectors: failedDetectors,
-	}
-
-	result := types.ConfirmationRes{} //nolint: exhaustruct
-	client := resty.New()
-	response, err := client.R().
-		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", authProvider+" "+authToken).
-		SetBody(scanPostReq).
-		SetResult(&result).
-		Post(urlEndpointPostScan)
-
-	if err != nil {
-		return err
-	}
-
-	if response.StatusCode() != http.StatusCreated {
-		return errors.New("received bad status code")
-	}
+func postScan(ctx context.Context, authToken string, scanPostReq types.ScanPostReq) error {
+	return wrapRejectedReportError(api.PostScan(ctx, authToken, scanPostReq))
+}
 
-	if result.Status != "created" {
-		return errors.New("received bad status response")
+// Wraps errors that will not go away by sending the report again in errReportRejected.
+func wrapRejectedReportError(err error) error {
+	var apiError *api.Error
+	// Reports sent with an invalid token can be sent again after logging in again.
+	if errors.As(err, &apiError) && !apiError.IsRetryable() && !apiError.IsUnauthorized() {
+		return fmt.Errorf("%w: %w", errReportRejected, err)
 	}
 
-	fmt.Println("done")
-
-	return nil
+	return err
 }
 
-func ReportScanIfApplicable(directoryToScan string,
-	unifiedFindingsNotIgnored []types.UnifiedFinding, failedDetectors []string) error {
+/**
+ * Reports the scan and previously queued reports. If the server cannot be reached,
+ * the report is queued in the outbox to be sent by a later scan or by report flush.
+ */
+func ReportScanIfApplicable(ctx context.Context, directoryToScan string,
+	unifiedFindingsNotIgnored []types.UnifiedFinding, failedDetectors []types.DetectorTermination) error {
 	authToken, err := login.GetAuthToken()
 	if err != nil {
 		return err
 	}
 
+	if authToken == "" {
+		return nil
+	}
+
 	assetName, err := getAssetName(directoryToScan)
 	if err != nil {
 		return err
@@ -76,15 +58,46 @@ func ReportScanIfApplicable(directoryToScan string,
 		return err
 	}
 
-	if authToken != "" {
-		err = ReportScan(authToken, assetName, assetRemoteUrls,
-			branch, revision, unifiedFindingsNotIgnored, failedDetectors)
-		if err != nil {
+	failedDetectorNames := functional.Map(failedDetectors,
+		func(failedDetector types.DetectorTermination) string {
+			return failedDetector.Detector
+		})
+
+	scanPostReq := types.ScanPostReq{
+		AssetName:       assetName,
+		AssetRemoteUrls: assetRemoteUrls,
+		Branch:          branch,
+		Revision:        revision,
+		Findings:        unifiedFindingsNotIgnored,
+		FailedDetectors: failedDetectorNames,
+	}
+
+	progress := UploadProgress{UploadId: 0, UploadedFindingCount: 0}
+	if config.Offline {
+		return enqueueReport(scanPostReq, progress, api.ErrOffline)
+	}
+
+	logging.Progress("Sending scan report to server")
+	err = uploadScan(ctx, authToken, scanPostReq, &progress)
+	if err != nil {
+		logging.ProgressDone("failed")
+
+		// Sending it again later would not help.
+		if errors.Is(err, errReportRejected) {
 			return err
 		}
+
+		return enqueueReport(scanPostReq, progress, err)
+	}
+	logging.ProgressDone("done")
+
+	// The server is reachable, so this is a good time to send reports that failed before.
+	sentCount, remainingCount, err := flushOutbox(ctx, authToken)
+	if sentCount > 0 || remainingCount > 0 {
+		logging.Info(fmt.Sprintf("Sent %d queued scan reports; %d remaining", sentCount, remainingCount))
 	}
 
-	return nil
+	return err
 }
 
 func getAssetName(directoryToScan string) (string, error) {
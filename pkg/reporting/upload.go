@@ -0,0 +1,86 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/secguro/secguro-cli/pkg/api"
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/logging"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+// Allows resuming an upload in batches after a failed batch.
+type UploadProgress struct {
+	UploadId             uint // 0 if the upload has not been started
+	UploadedFindingCount int
+}
+
+/**
+ * Sends small reports in a single request and large ones in batches. progress is
+ * updated after each batch so that a failed upload can be resumed by passing it again.
+ */
+func uploadScan(ctx context.Context, authToken string, scanPostReq types.ScanPostReq,
+	progress *UploadProgress) error {
+	if len(scanPostReq.Findings) <= config.ReportFindingsBatchSize && progress.UploadId == 0 {
+		return postScan(ctx, authToken, scanPostReq)
+	}
+
+	err := uploadScanInBatches(ctx, authToken, scanPostReq, progress)
+
+	var apiError *api.Error
+	if errors.As(err, &apiError) && apiError.IsNotFound() {
+		// Servers without support for uploads in batches only accept single requests.
+		if progress.UploadId == 0 {
+			return postScan(ctx, authToken, scanPostReq)
+		}
+
+		// The server discards uploads that are not committed in time.
+		logging.ProgressUpdate("upload expired, starting over...")
+		*progress = UploadProgress{UploadId: 0, UploadedFindingCount: 0}
+		err = uploadScanInBatches(ctx, authToken, scanPostReq, progress)
+	}
+
+	return wrapRejectedReportError(err)
+}
+
+func uploadScanInBatches(ctx context.Context, authToken string, scanPostReq types.ScanPostReq,
+	progress *UploadProgress) error {
+	findingCount := len(scanPostReq.Findings)
+
+	if progress.UploadId == 0 {
+		scanUploadRes, err := api.PostScanUpload(ctx, authToken, types.ScanUploadPostReq{
+			AssetName:       scanPostReq.AssetName,
+			AssetRemoteUrls: scanPostReq.AssetRemoteUrls,
+			Branch:          scanPostReq.Branch,
+			Revision:        scanPostReq.Revision,
+			FailedDetectors: scanPostReq.FailedDetectors,
+			FindingCount:    findingCount,
+		})
+		if err != nil {
+			return err
+		}
+
+		progress.UploadId = scanUploadRes.ID
+		progress.UploadedFindingCount = 0
+	}
+
+	for progress.UploadedFindingCount < findingCount {
+		offset := progress.UploadedFindingCount
+		batch := scanPostReq.Findings[offset:min(offset+config.ReportFindingsBatchSize, findingCount)]
+
+		err := api.PostScanUploadFindings(ctx, authToken, progress.UploadId, types.ScanUploadFindingsPostReq{
+			Offset:   offset,
+			Findings: batch,
+		})
+		if err != nil {
+			return err
+		}
+
+		progress.UploadedFindingCount += len(batch)
+		logging.ProgressUpdate(fmt.Sprintf("%d/%d...", progress.UploadedFindingCount, findingCount))
+	}
+
+	return api.PostScanUploadCommit(ctx, authToken, progress.UploadId)
+}
@@ -0,0 +1,49 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/secguro/secguro-cli/pkg/baseline"
+	"github.com/secguro/secguro-cli/pkg/logging"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+func CommandBaselineCreate(ctx context.Context, scanOptions types.ScanOptions,
+	disabledDetectors []string, timeouts Timeouts, baselinePath string) error {
+	unifiedFindingsNotIgnored, failedDetectors, err := PerformScan(ctx, scanOptions, disabledDetectors, timeouts)
+	if err != nil {
+		return err
+	}
+
+	if baselinePath == "" {
+		baselinePath = scanOptions.DirectoryToScan + "/" + baseline.DefaultFileName
+	}
+
+	err = baseline.WriteBaseline(baselinePath, baseline.CreateBaseline(unifiedFindingsNotIgnored))
+	if err != nil {
+		return err
+	}
+
+	logging.Info(fmt.Sprintf("Baseline with %d findings written to: %s", len(unifiedFindingsNotIgnored), baselinePath))
+
+	if len(failedDetectors) != 0 {
+		logging.Warn("Be mindful that some detectors have failed. Their findings are missing from the baseline.")
+	}
+
+	return nil
+}
+
+func getFindingsNotInBaseline(baselinePath string,
+	unifiedFindings []types.UnifiedFinding) ([]types.UnifiedFinding, error) {
+	existingBaseline, err := baseline.ReadBaseline(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+
+	unifiedFindingsNotInBaseline := baseline.GetFindingsNotInBaseline(existingBaseline, unifiedFindings)
+	logging.Info(fmt.Sprintf("Omitting %d findings recorded in baseline %s",
+		len(unifiedFindings)-len(unifiedFindingsNotInBaseline), baselinePath))
+
+	return unifiedFindingsNotInBaseline, nil
+}
@@ -0,0 +1,171 @@
+package scan
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/secguro/secguro-cli/pkg/redaction"
+	"github.com/secguro/secguro-cli/pkg/types"
+)
+
+/**
+ * Merges findings that describe the same problem: In git mode, a secret is reported once
+ * per commit it appears in; these occurrences are folded into a single finding. Secrets
+ * that several detectors (or rules) report at the same location are merged into a single
+ * finding listing all contributing detectors and rules.
+ */
+func mergeFindings(unifiedFindings []types.UnifiedFinding) []types.UnifiedFinding {
+	return mergeFindingsAcrossDetectors(foldHistoricalOccurrences(unifiedFindings))
+}
+
+/**
+ * Occurrences that only exist in the git history are folded into the first finding of the
+ * same secret. Occurrences that are still present in the working directory are only folded
+ * if they are at the same location.
+ */
+func foldHistoricalOccurrences(unifiedFindings []types.UnifiedFinding) []types.UnifiedFinding {
+	result := make([]types.UnifiedFinding, 0, len(unifiedFindings))
+	indexesBySecret := make(map[string]int)
+	indexesBySecretAndLocation := make(map[string]int)
+
+	for _, unifiedFinding := range unifiedFindings {
+		// Only findings from git mode have git info.
+		if unifiedFinding.GitInfo == nil || !redaction.IsSecretFinding(unifiedFinding) {
+			result = append(result, unifiedFinding)
+			continue
+		}
+
+		secretKey := getSecretKey(unifiedFinding)
+		index, exists := indexesBySecret[secretKey]
+
+		if unifiedFinding.File != "" {
+			secretAndLocationKey := secretKey + "\x00" +
+				unifiedFinding.File + ":" + strconv.Itoa(unifiedFinding.LineStart)
+			if indexOfSameLocation, existsAtSameLocation :=
+				indexesBySecretAndLocation[secretAndLocationKey]; existsAtSameLocation {
+				index, exists = indexOfSameLocation, true
+			} else if exists && result[index].File != "" {
+				// The secret is present at another location in the working directory.
+				exists = false
+			}
+
+			if !exists {
+				index = len(result)
+			}
+			indexesBySecretAndLocation[secretAndLocationKey] = index
+		}
+
+		if !exists {
+			if _, isSecretKnown := indexesBySecret[secretKey]; !isSecretKnown {
+				indexesBySecret[secretKey] = len(result)
+			}
+			result = append(result, unifiedFinding)
+
+			continue
+		}
+
+		result[index] = addOccurrence(result[index], unifiedFinding)
+	}
+
+	return result
+}
+
+func getSecretKey(unifiedFinding types.UnifiedFinding) string {
+	return strings.Join([]string{
+		unifiedFinding.Detector,
+		unifiedFinding.Rule,
+		strings.TrimSpace(unifiedFinding.Match),
+	}, "\x00")
+}
+
+// The occurrence that still exists in the working directory (if any) remains the finding itself.
+func addOccurrence(unifiedFinding types.UnifiedFinding,
+	otherUnifiedFinding types.UnifiedFinding) types.UnifiedFinding {
+	if unifiedFinding.File == "" && otherUnifiedFinding.File != "" {
+		unifiedFinding, otherUnifiedFinding = otherUnifiedFinding, unifiedFinding
+	}
+
+	occurrences := make([]types.Occurrence, 0,
+		len(unifiedFinding.Occurrences)+1+len(otherUnifiedFinding.Occurrences))
+	occurrences = append(occurrences, unifiedFinding.Occurrences...)
+	occurrences = append(occurrences, types.Occurrence{
+		File:        otherUnifiedFinding.File,
+		LineStart:   otherUnifiedFinding.LineStart,
+		LineEnd:     otherUnifiedFinding.LineEnd,
+		ColumnStart: otherUnifiedFinding.ColumnStart,
+		GitInfo:     otherUnifiedFinding.GitInfo,
+	})
+	occurrences = append(occurrences, otherUnifiedFinding.Occurrences...)
+	unifiedFinding.Occurrences = occurrences
+
+	return unifiedFinding
+}
+
+func mergeFindingsAcrossDetectors(unifiedFindings []types.UnifiedFinding) []types.UnifiedFinding {
+	result := make([]types.UnifiedFinding, 0, len(unifiedFindings))
+	indexesByLocation := make(map[string][]int)
+
+	for _, unifiedFinding := range unifiedFindings {
+		if unifiedFinding.File == "" || !redaction.IsSecretFinding(unifiedFinding) {
+			result = append(result, unifiedFinding)
+			continue
+		}
+
+		location := unifiedFinding.File + ":" + strconv.Itoa(unifiedFinding.LineStart)
+		merged := false
+		for _, index := range indexesByLocation[location] {
+			if isSameSecret(result[index].Match, unifiedFinding.Match) {
+				result[index] = mergeDetections(result[index], unifiedFinding)
+				merged = true
+
+				break
+			}
+		}
+
+		if !merged {
+			indexesByLocation[location] = append(indexesByLocation[location], len(result))
+			result = append(result, unifiedFinding)
+		}
+	}
+
+	return result
+}
+
+// Detectors differ in how much of the line they report as the match (e.g. semgrep reports whole lines).
+func isSameSecret(match string, otherMatch string) bool {
+	match = strings.TrimSpace(match)
+	otherMatch = strings.TrimSpace(otherMatch)
+
+	if match == "" || otherMatch == "" {
+		return false
+	}
+
+	return strings.Contains(match, otherMatch) || strings.Contains(otherMatch, match)
+}
+
+/**
+ * The detection with the higher severity (or, if equal, the detector whose name comes first)
+ * remains the finding itself so that the result does not depend on the order in which the
+ * detectors finish.
+ */
+func mergeDetections(unifiedFinding types.UnifiedFinding,
+	otherUnifiedFinding types.UnifiedFinding) types.UnifiedFinding {
+	severity := types.NormalizeSeverity(unifiedFinding.Severity)
+	otherSeverity := types.NormalizeSeverity(otherUnifiedFinding.Severity)
+	if otherSeverity > severity ||
+		(otherSeverity == severity && otherUnifiedFinding.Detector < unifiedFinding.Detector) {
+		unifiedFinding, otherUnifiedFinding = otherUnifiedFinding, unifiedFinding
+	}
+
+	unifiedFinding.OtherDetections = append(append(unifiedFinding.OtherDetections, types.Detection{
+		Detector: otherUnifiedFinding.Detector,
+		Rule:     otherUnifiedFinding.Rule,
+	}), otherUnifiedFinding.OtherDetections...)
+	unifiedFinding.Occurrences = append(unifiedFinding.Occurrences, otherUnifiedFinding.Occurrences...)
+
+	if unifiedFinding.Hint == "" {
+		unifiedFinding.Hint = otherUnifiedFinding.Hint
+	}
+
+	return unifiedFinding
+}
@@ -1,78 +1,163 @@
 package scan
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	ignore "github.com/sabhiram/go-gitignore"
 	"github.com/secguro/secguro-cli/pkg/dependencies"
-	"github.com/secguro/secguro-cli/pkg/dependencycheck"
+	"github.com/secguro/secguro-cli/pkg/detectors"
+	"github.com/secguro/secguro-cli/pkg/fingerprint"
 	"github.com/secguro/secguro-cli/pkg/functional"
-	"github.com/secguro/secguro-cli/pkg/gitleaks"
+	"github.com/secguro/secguro-cli/pkg/git"
 	"github.com/secguro/secguro-cli/pkg/ignoring"
+	"github.com/secguro/secguro-cli/pkg/logging"
 	"github.com/secguro/secguro-cli/pkg/output"
+	"github.com/secguro/secguro-cli/pkg/redaction"
 	"github.com/secguro/secguro-cli/pkg/reporting"
-	"github.com/secguro/secguro-cli/pkg/semgrep"
 	"github.com/secguro/secguro-cli/pkg/types"
+	"github.com/secguro/secguro-cli/pkg/utils"
 )
 
 const maxFindingsIndicatingExitCode = 250
 
-func CommandScan(directoryToScan string, gitMode bool, disabledDetectors []string,
-	printAsJson bool, outputDestination string, tolerance int) error {
-	unifiedFindingsNotIgnored, failedDetectors, err := PerformScan(directoryToScan, gitMode, disabledDetectors)
+type CommandScanOptions struct {
+	BaselinePath      string // empty string signifies not using a baseline
+	Format            string
+	OutputDestination string
+	Tolerance         int
+	MinSeverity       types.Severity // findings below are neither shown nor counted
+	FailOn            types.Severity // findings below are shown but not counted for the exit code
+	ShowSecrets       bool           // disables redaction of secrets in output and reports
+}
+
+func CommandScan(ctx context.Context, scanOptions types.ScanOptions, disabledDetectors []string,
+	timeouts Timeouts, commandScanOptions CommandScanOptions) error {
+	unifiedFindingsNotIgnored, failedDetectors, err := PerformScan(ctx, scanOptions, disabledDetectors, timeouts)
 	if err != nil {
 		return err
 	}
 
-	err = writeOutput(gitMode, printAsJson, outputDestination, unifiedFindingsNotIgnored)
+	if commandScanOptions.BaselinePath != "" {
+		unifiedFindingsNotIgnored, err = getFindingsNotInBaseline(commandScanOptions.BaselinePath,
+			unifiedFindingsNotIgnored)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Baselines are applied before redaction because fingerprints are based on the matches.
+	if !commandScanOptions.ShowSecrets {
+		unifiedFindingsNotIgnored = redaction.RedactFindings(unifiedFindingsNotIgnored)
+	}
+
+	unifiedFindingsToShow := getFindingsWithMinSeverity(unifiedFindingsNotIgnored, commandScanOptions.MinSeverity)
+
+	err = writeOutput(scanOptions.GitMode, commandScanOptions.Format, commandScanOptions.OutputDestination,
+		unifiedFindingsToShow)
 	if err != nil {
 		return err
 	}
 
-	err = reporting.ReportScanIfApplicable(directoryToScan, unifiedFindingsNotIgnored, failedDetectors)
+	// Failing to report must not hide the findings from the exit code.
+	err = reporting.ReportScanIfApplicable(ctx, scanOptions.DirectoryToScan, unifiedFindingsNotIgnored,
+		failedDetectors)
 	if err != nil {
-		return err
+		logging.Warn("Warning: failed to report scan: " + err.Error())
 	}
 
 	if len(failedDetectors) != 0 {
-		fmt.Println("Be mindful that some detectors have failed. Confer top of output.")
+		logging.Warn("Be mindful that some detectors have failed. Confer top of output.")
 	}
 
-	exitWithAppropriateExitCode(len(unifiedFindingsNotIgnored), tolerance)
+	unifiedFindingsToCount := getFindingsWithMinSeverity(unifiedFindingsToShow, commandScanOptions.FailOn)
+	exitWithAppropriateExitCode(len(unifiedFindingsToCount), commandScanOptions.Tolerance)
 
 	return nil
 }
 
-func PerformScan(directoryToScan string,
-	gitMode bool, disabledDetectors []string) ([]types.UnifiedFinding, []string, error) {
-	fmt.Print("Downloading and extracting dependencies...")
-	err := dependencies.InstallDependencies(disabledDetectors)
+func getFindingsWithMinSeverity(unifiedFindings []types.UnifiedFinding,
+	minSeverity types.Severity) []types.UnifiedFinding {
+	return functional.Filter(unifiedFindings, func(unifiedFinding types.UnifiedFinding) bool {
+		return types.NormalizeSeverity(unifiedFinding.Severity) >= minSeverity
+	})
+}
+
+func PerformScan(ctx context.Context, scanOptions types.ScanOptions, disabledDetectors []string,
+	timeouts Timeouts) ([]types.UnifiedFinding, []types.DetectorTermination, error) {
+	enabledDetectors := detectors.GetEnabled(disabledDetectors)
+
+	logging.Progress("Downloading and extracting dependencies")
+	err := dependencies.InstallDependencies(enabledDetectors)
 	if err != nil {
+		logging.ProgressDone("failed")
 		return nil, nil, err
 	}
-	fmt.Println("done")
+	logging.ProgressDone("done")
+
+	scanOptions, err = restrictToChangedFiles(scanOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logging.Progress("Scanning")
+	unifiedFindings, failedDetectors := getUnifiedFindings(ctx, enabledDetectors, scanOptions, timeouts)
+	// Abort if the user has cancelled the scan (as opposed to the scan having timed out).
+	if errors.Is(ctx.Err(), context.Canceled) {
+		logging.ProgressDone("cancelled")
+		return nil, nil, ctx.Err()
+	}
 
-	fmt.Print("Scanning...")
-	unifiedFindings, failedDetectors := getUnifiedFindings(directoryToScan, gitMode, disabledDetectors)
 	if len(failedDetectors) == 0 {
-		fmt.Println("done")
+		logging.ProgressDone("done")
 	} else {
-		fmt.Println("done with errors: the following detectors failed:")
+		logging.ProgressDone("done with errors")
+		logging.Warn("The following detectors failed:")
 		for _, failedDetector := range failedDetectors {
-			fmt.Println("  • " + failedDetector)
+			logging.Warn("  • " + failedDetector.Detector + ": " + failedDetector.Reason)
 		}
 	}
 
-	unifiedFindingsNotIgnored, err := getFindingsNotIgnored(directoryToScan, unifiedFindings)
+	unifiedFindingsNotIgnored, err := getFindingsNotIgnored(scanOptions.DirectoryToScan, unifiedFindings)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	unifiedFindingsNotIgnored = mergeFindings(unifiedFindingsNotIgnored)
+	unifiedFindingsNotIgnored = fingerprint.AddFingerprints(scanOptions.DirectoryToScan, unifiedFindingsNotIgnored)
+
 	return unifiedFindingsNotIgnored, failedDetectors, nil
 }
 
+func restrictToChangedFiles(scanOptions types.ScanOptions) (types.ScanOptions, error) {
+	var changedFiles []string
+	var err error
+	switch {
+	case scanOptions.Staged:
+		changedFiles, err = git.GetStagedFiles(scanOptions.DirectoryToScan)
+		if err != nil {
+			return scanOptions, errors.New("could not determine staged files: " + err.Error())
+		}
+	case scanOptions.Since != "":
+		changedFiles, err = git.GetFilesChangedSince(scanOptions.DirectoryToScan, scanOptions.Since)
+		if err != nil {
+			return scanOptions, errors.New("could not determine files changed since " +
+				scanOptions.Since + ": " + err.Error())
+		}
+	default:
+		return scanOptions, nil
+	}
+
+	logging.Info(fmt.Sprintf("Restricting scan to %d changed files", len(changedFiles)))
+	scanOptions.ChangedFiles = changedFiles
+
+	return scanOptions, nil
+}
+
 func exitWithAppropriateExitCode(numberOfFindingsNotIgnored int, tolerance int) {
 	if numberOfFindingsNotIgnored <= tolerance {
 		os.Exit(0)
@@ -85,57 +170,89 @@ func exitWithAppropriateExitCode(numberOfFindingsNotIgnored int, tolerance int)
 	os.Exit(numberOfFindingsNotIgnored)
 }
 
-func getUnifiedFindings(directoryToScan string, //nolint: cyclop
-	gitMode bool, disabledDetectors []string) ([]types.UnifiedFinding, []string) {
-	failedDetectors := make([]string, 0)
+type detectorResult struct {
+	detectorTermination types.DetectorTermination
+	unifiedFindings     []types.UnifiedFinding
+}
+
+func getUnifiedFindings(ctx context.Context, enabledDetectors []types.Detector,
+	scanOptions types.ScanOptions, timeouts Timeouts) ([]types.UnifiedFinding, []types.DetectorTermination) {
+	failedDetectors := make([]types.DetectorTermination, 0)
 	unifiedFindings := make([]types.UnifiedFinding, 0)
 
-	channelCapacity := 100
-	runningDetectors := 0
-	unifiedFindingsChannel := make(chan types.UnifiedFinding, channelCapacity)
-	detectorTerminationChannel := make(chan types.DetectorTermination, channelCapacity)
+	scanCtx, cancelScanCtx := timeouts.getScanContext(ctx)
+	defer cancelScanCtx()
+
+	detectorResultsChannel := make(chan detectorResult, len(enabledDetectors))
 
-	if !functional.ArrayIncludes(disabledDetectors, "gitleaks") {
-		go gitleaks.GetGitleaksFindingsAsUnified(directoryToScan, gitMode,
-			unifiedFindingsChannel, detectorTerminationChannel)
-		runningDetectors++
+	for _, detector := range enabledDetectors {
+		go func() {
+			detectorResultsChannel <- runDetector(scanCtx, detector, scanOptions, timeouts)
+		}()
 	}
 
-	if !functional.ArrayIncludes(disabledDetectors, "semgrep") {
-		go semgrep.GetSemgrepFindingsAsUnified(directoryToScan, gitMode,
-			unifiedFindingsChannel, detectorTerminationChannel)
-		runningDetectors++
+	for range enabledDetectors {
+		detectorResult := <-detectorResultsChannel
+		if !detectorResult.detectorTermination.Successful {
+			failedDetectors = append(failedDetectors, detectorResult.detectorTermination)
+
+			continue
+		}
+
+		unifiedFindings = append(unifiedFindings, detectorResult.unifiedFindings...)
 	}
 
-	if !functional.ArrayIncludes(disabledDetectors, "dependencycheck") {
-		go dependencycheck.GetDependencycheckFindingsAsUnified(directoryToScan, gitMode,
-			unifiedFindingsChannel, detectorTerminationChannel)
-		runningDetectors++
+	return unifiedFindings, failedDetectors
+}
+
+func runDetector(scanCtx context.Context, detector types.Detector,
+	scanOptions types.ScanOptions, timeouts Timeouts) detectorResult {
+	detectorCtx, cancelDetectorCtx := timeouts.getDetectorContext(scanCtx, detector.Name())
+	defer cancelDetectorCtx()
+
+	startTime := time.Now()
+	unifiedFindings, err := detector.Scan(detectorCtx, scanOptions)
+	duration := time.Since(startTime)
+	if err == nil {
+		logging.Verbose("detector finished", "detector", detector.Name(), "duration", duration,
+			"findings", len(unifiedFindings))
+
+		return detectorResult{
+			detectorTermination: types.DetectorTermination{
+				Detector:   detector.Name(),
+				Successful: true,
+				Reason:     "",
+				Details:    "",
+			},
+			unifiedFindings: unifiedFindings,
+		}
 	}
 
-	for {
-		select {
-		case unifiedFinding, ok := <-unifiedFindingsChannel:
-			if ok {
-				unifiedFindings = append(unifiedFindings, unifiedFinding)
-			} else {
-				return unifiedFindings, failedDetectors
-			}
+	reason := err.Error()
+	switch {
+	case errors.Is(detectorCtx.Err(), context.DeadlineExceeded):
+		reason = "timed out"
+	case errors.Is(detectorCtx.Err(), context.Canceled):
+		reason = "cancelled"
+	}
 
-		case detectorTermination := <-detectorTerminationChannel:
-			runningDetectors--
-			if !detectorTermination.Successful {
-				failedDetectors = append(failedDetectors, detectorTermination.Detector)
-			}
-			if runningDetectors == 0 {
-				close(unifiedFindingsChannel)
-			}
+	details := ""
+	var commandError *utils.CommandError
+	if errors.As(err, &commandError) {
+		details = commandError.Stderr
+	}
 
-		default:
-			if runningDetectors == 0 {
-				return unifiedFindings, failedDetectors
-			}
-		}
+	logging.Verbose("detector failed", "detector", detector.Name(), "duration", duration, "error", err,
+		"details", details)
+
+	return detectorResult{
+		detectorTermination: types.DetectorTermination{
+			Detector:   detector.Name(),
+			Successful: false,
+			Reason:     reason,
+			Details:    details,
+		},
+		unifiedFindings: nil,
 	}
 }
 
@@ -198,21 +315,25 @@ func getFindingsNotIgnored(directoryToScan string, //nolint: cyclop
 	return unifiedFindingsNotIgnored, nil
 }
 
-func writeOutput(gitMode bool, printAsJson bool,
+func writeOutput(gitMode bool, format string,
 	outputDestination string, unifiedFindingsNotIgnored []types.UnifiedFinding) error {
 	var outputString string
-	if printAsJson {
-		var err error
+	var err error
+	switch format {
+	case "json":
 		outputString, err = output.PrintJson(unifiedFindingsNotIgnored, gitMode)
-		if err != nil {
-			return err
-		}
-	} else {
+	case "sarif":
+		outputString, err = output.PrintSarif(unifiedFindingsNotIgnored, gitMode)
+	default:
 		outputString = output.PrintText(unifiedFindingsNotIgnored, gitMode)
 	}
+	if err != nil {
+		return err
+	}
 
 	if outputDestination == "" {
-		fmt.Println("Findings:")
+		// Only the findings go to stdout so that they can be processed by other tools.
+		logging.Info("Findings:")
 		fmt.Println(outputString)
 	} else {
 		const filePermissions = 0644
@@ -221,7 +342,7 @@ func writeOutput(gitMode bool, printAsJson bool,
 			return err
 		}
 
-		fmt.Println("Output written to: " + outputDestination)
+		logging.Info("Output written to: " + outputDestination)
 	}
 
 	return nil
@@ -0,0 +1,58 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/secguro/secguro-cli/pkg/detectors"
+)
+
+type Timeouts struct {
+	Scan      time.Duration            // 0 signifies no timeout
+	Detectors map[string]time.Duration // detectors without entry only have the scan timeout
+}
+
+// Parses values of the form "detector=duration" (e.g. "dependencycheck=20m").
+func ParseDetectorTimeouts(values []string) (map[string]time.Duration, error) {
+	detectorTimeouts := make(map[string]time.Duration)
+	for _, value := range values {
+		detectorName, durationString, found := strings.Cut(value, "=")
+		if !found {
+			return nil, errors.New("invalid detector timeout (expected detector=duration): " + value)
+		}
+
+		err := detectors.ValidateDetectorNames([]string{detectorName})
+		if err != nil {
+			return nil, err
+		}
+
+		duration, err := time.ParseDuration(durationString)
+		if err != nil {
+			return nil, errors.New("invalid duration for detector timeout: " + value)
+		}
+
+		detectorTimeouts[detectorName] = duration
+	}
+
+	return detectorTimeouts, nil
+}
+
+func (timeouts Timeouts) getScanContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return getContextWithOptionalTimeout(ctx, timeouts.Scan)
+}
+
+func (timeouts Timeouts) getDetectorContext(scanCtx context.Context,
+	detectorName string) (context.Context, context.CancelFunc) {
+	return getContextWithOptionalTimeout(scanCtx, timeouts.Detectors[detectorName])
+}
+
+func getContextWithOptionalTimeout(ctx context.Context,
+	timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
@@ -1,17 +1,21 @@
 package semgrep
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"os"
-	"os/exec"
+	"runtime"
 
+	"github.com/secguro/secguro-cli/pkg/config"
+	"github.com/secguro/secguro-cli/pkg/dependencies"
 	"github.com/secguro/secguro-cli/pkg/functional"
 	"github.com/secguro/secguro-cli/pkg/git"
 	"github.com/secguro/secguro-cli/pkg/types"
+	"github.com/secguro/secguro-cli/pkg/utils"
 )
 
+const detectorName = "semgrep"
+
 type Meta_SemgrepFinding struct {
 	Results []SemgrepFinding
 }
@@ -35,16 +39,15 @@ type SemgrepFinding_extra struct {
 	Severity string
 }
 
-func convertSemgrepFindingToUnifiedFinding(directoryToScan string, gitMode bool,
+func convertSemgrepFindingToUnifiedFinding(blameCache *git.BlameCache,
 	semgrepFinding SemgrepFinding) (types.UnifiedFinding, error) {
-	gitInfo, err := git.GetGitInfo(directoryToScan, gitMode,
-		"", semgrepFinding.Path, semgrepFinding.Start.Line, false)
+	gitInfo, err := blameCache.GetGitInfo("", semgrepFinding.Path, semgrepFinding.Start.Line, false)
 	if err != nil {
 		return types.UnifiedFinding{}, err
 	}
 
 	unifiedFinding := types.UnifiedFinding{
-		Detector:             "semgrep",
+		Detector:             detectorName,
 		IdOnExternalPlatform: nil,
 		Rule:                 semgrepFinding.Check_id,
 		File:                 "/" + semgrepFinding.Path,
@@ -56,12 +59,15 @@ func convertSemgrepFindingToUnifiedFinding(directoryToScan string, gitMode bool,
 		Hint:                 semgrepFinding.Extra.Message,
 		Severity:             semgrepFinding.Extra.Severity,
 		GitInfo:              gitInfo,
+		Fingerprint:          "",
+		OtherDetections:      nil,
+		Occurrences:          nil,
 	}
 
 	return unifiedFinding, nil
 }
 
-func getSemgrepOutputJson(directoryToScan string) ([]byte, error) {
+func getSemgrepOutputJson(ctx context.Context, scanOptions types.ScanOptions) ([]byte, error) {
 	tmpDir, err := os.MkdirTemp("", "")
 	if err != nil {
 		return nil, err
@@ -69,67 +75,83 @@ func getSemgrepOutputJson(directoryToScan string) ([]byte, error) {
 	defer os.RemoveAll(tmpDir)
 	semgrepOutputJsonPath := tmpDir + "/semgrepOutput.json"
 
-	cmd := exec.Command("semgrep", "scan", "--json", "-o", semgrepOutputJsonPath)
-	cmd.Dir = directoryToScan
-	// Ignore error because this is expected to deliver an exit code not equal to 0 and write to stderr.
-	out, _ := cmd.Output()
-	if len(out) != 0 {
-		return nil, errors.New("received unexpected output from semgrep")
+	args := []string{"scan", "--json", "-o", semgrepOutputJsonPath}
+	if config.Offline {
+		// The default rules would be fetched from the Semgrep registry.
+		semgrepRulesFilePath, err := dependencies.GetSemgrepRulesFilePath()
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, "--metrics", "off", "--disable-version-check", "--config", semgrepRulesFilePath)
+		for _, customRulePath := range scanOptions.CustomRulePaths {
+			args = append(args, "--config", customRulePath)
+		}
+	} else if len(scanOptions.CustomRulePaths) > 0 {
+		// Keep the default rules in addition to the custom rules.
+		args = append(args, "--config", "auto")
+		for _, customRulePath := range scanOptions.CustomRulePaths {
+			args = append(args, "--config", customRulePath)
+		}
+	}
+	if scanOptions.IsRestrictedToChangedFiles() {
+		args = append(append(args, "--"), scanOptions.ChangedFiles...)
 	}
 
-	semgrepOutputJson, err := os.ReadFile(semgrepOutputJsonPath)
+	semgrepExecutablePath, err := dependencies.GetSemgrepExecutablePath()
+	if err != nil {
+		return nil, err
+	}
 
-	return semgrepOutputJson, err
-}
+	cmd := utils.CommandContext(ctx, semgrepExecutablePath, args...)
+	cmd.Dir = scanOptions.DirectoryToScan
+	_, err = utils.CommandOutput(cmd)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 
-func GetSemgrepFindingsAsUnified(directoryToScan string, gitMode bool,
-	unifiedFindingsChannel chan types.UnifiedFinding,
-	detectorTerminationChannel chan types.DetectorTermination) {
-	semgrepOutputJson, err := getSemgrepOutputJson(directoryToScan)
+	// Without --error, semgrep exits with exit code 0 whether there are findings or not.
+	err = utils.CheckExitCode(detectorName, err)
 	if err != nil {
-		fmt.Println(err)
-		detectorTerminationChannel <- types.DetectorTermination{
-			Detector:   "semgrep",
-			Successful: false,
-		}
+		return nil, err
+	}
+
+	return utils.ReadReportFile(detectorName, semgrepOutputJsonPath)
+}
+
+type Detector struct{}
+
+func (Detector) Name() string {
+	return detectorName
+}
 
-		return
+func (Detector) Install() error {
+	return dependencies.InstallSemgrep()
+}
+
+func (Detector) Scan(ctx context.Context, scanOptions types.ScanOptions) ([]types.UnifiedFinding, error) {
+	// Without targets, semgrep would scan the whole directory.
+	if scanOptions.IsRestrictedToChangedFiles() && len(scanOptions.ChangedFiles) == 0 {
+		return make([]types.UnifiedFinding, 0), nil
+	}
+
+	semgrepOutputJson, err := getSemgrepOutputJson(ctx, scanOptions)
+	if err != nil {
+		return nil, err
 	}
 
 	var metaSemgrepFindings Meta_SemgrepFinding
 	err = json.Unmarshal(semgrepOutputJson, &metaSemgrepFindings)
 	if err != nil {
-		fmt.Println(err)
-		detectorTerminationChannel <- types.DetectorTermination{
-			Detector:   "semgrep",
-			Successful: false,
-		}
-
-		return
+		return nil, err
 	}
 
 	semgrepFindings := metaSemgrepFindings.Results
 
-	unifiedFindings, err := functional.MapWithError(semgrepFindings,
+	blameCache := git.NewBlameCache(scanOptions.DirectoryToScan, scanOptions.GitMode)
+
+	return functional.MapWithErrorInParallel(semgrepFindings, runtime.NumCPU(),
 		func(semgrepFinding SemgrepFinding) (types.UnifiedFinding, error) {
-			return convertSemgrepFindingToUnifiedFinding(directoryToScan, gitMode, semgrepFinding)
+			return convertSemgrepFindingToUnifiedFinding(blameCache, semgrepFinding)
 		})
-	if err != nil {
-		fmt.Println(err)
-		detectorTerminationChannel <- types.DetectorTermination{
-			Detector:   "semgrep",
-			Successful: false,
-		}
-
-		return
-	}
-
-	for _, unifiedFinding := range unifiedFindings {
-		unifiedFindingsChannel <- unifiedFinding
-	}
-
-	detectorTerminationChannel <- types.DetectorTermination{
-		Detector:   "semgrep",
-		Successful: true,
-	}
 }
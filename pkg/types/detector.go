@@ -0,0 +1,23 @@
+package types
+
+import "context"
+
+type Detector interface {
+	Name() string
+	// Downloads or installs whatever the detector needs to run.
+	Install() error
+	Scan(ctx context.Context, scanOptions ScanOptions) ([]UnifiedFinding, error)
+}
+
+type ScanOptions struct {
+	DirectoryToScan string
+	GitMode         bool
+	Since           string   // empty string signifies not restricting the scan to changes since a revision
+	Staged          bool     // restricts the scan to staged changes
+	ChangedFiles    []string // nil signifies scanning all files; paths are relative to DirectoryToScan
+	CustomRulePaths []string // absolute paths of additional semgrep rule files or directories
+}
+
+func (scanOptions ScanOptions) IsRestrictedToChangedFiles() bool {
+	return scanOptions.ChangedFiles != nil
+}
@@ -9,6 +9,21 @@ type ScanPostReq struct {
 	FailedDetectors []string
 }
 
+// Used instead of ScanPostReq for reports with many findings.
+type ScanUploadPostReq struct {
+	AssetName       string
+	AssetRemoteUrls []string
+	Branch          string
+	Revision        string
+	FailedDetectors []string
+	FindingCount    int
+}
+
+type ScanUploadFindingsPostReq struct {
+	Offset   int // index of the first finding of the batch; batches sent twice are ignored
+	Findings []UnifiedFinding
+}
+
 type DevicePostReq struct {
 	DeviceName string
 }
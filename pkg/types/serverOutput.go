@@ -4,6 +4,10 @@ type ConfirmationRes struct {
 	Status string
 }
 
+type ScanUploadRes struct {
+	ID uint
+}
+
 type DevicePostRes struct {
 	ID            uint
 	Name          string
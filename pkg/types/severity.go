@@ -0,0 +1,62 @@
+package types
+
+import (
+	"errors"
+	"strings"
+)
+
+// Normalized severity of findings; detectors map their own severity scales onto it.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:     "INFO",
+	SeverityLow:      "LOW",
+	SeverityMedium:   "MEDIUM",
+	SeverityHigh:     "HIGH",
+	SeverityCritical: "CRITICAL",
+}
+
+func (severity Severity) String() string {
+	return severityNames[severity]
+}
+
+// Parses a normalized severity name as given by the user (case-insensitive).
+func ParseSeverity(severityName string) (Severity, error) {
+	for severity, name := range severityNames {
+		if strings.EqualFold(name, severityName) {
+			return severity, nil
+		}
+	}
+
+	return SeverityInfo, errors.New("unknown severity (valid severities: info, low, medium, high, critical): " +
+		severityName)
+}
+
+// Maps the severity names of the detectors (e.g. semgrep's ERROR/WARNING/INFO) as well as
+// the normalized severity names onto the normalized severity scale. Normalizing the name
+// of a normalized severity yields the same severity.
+func NormalizeSeverity(severityName string) Severity {
+	switch strings.ToUpper(severityName) {
+	case "CRITICAL":
+		return SeverityCritical
+	case "HIGH", "ERROR":
+		return SeverityHigh
+	case "MEDIUM", "MODERATE", "WARNING":
+		return SeverityMedium
+	case "LOW":
+		return SeverityLow
+	case "INFO", "INFORMATIONAL", "NONE":
+		return SeverityInfo
+	default:
+		// Be conservative if the severity is unknown.
+		return SeverityMedium
+	}
+}
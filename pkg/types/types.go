@@ -23,9 +23,27 @@ type UnifiedFinding struct {
 	Hint                 string
 	Severity             string
 	GitInfo              *GitInfo
+	Fingerprint          string       // identifies the finding across scans; set after scanning
+	OtherDetections      []Detection  // further detectors and rules that reported the same problem
+	Occurrences          []Occurrence // further occurrences of the same secret in the git history
+}
+
+type Detection struct {
+	Detector string
+	Rule     string
+}
+
+type Occurrence struct {
+	File        string // empty if the occurrence does not exist in the working directory anymore
+	LineStart   int
+	LineEnd     int
+	ColumnStart int
+	GitInfo     *GitInfo
 }
 
 type DetectorTermination struct {
 	Detector   string
 	Successful bool
+	Reason     string // empty if successful
+	Details    string // e.g. the end of the detector's stderr; empty if successful or not available
 }
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/secguro/secguro-cli/pkg/logging"
+)
+
+// Time to wait for output pipes to be closed after the process of a cancelled command was killed.
+const commandWaitDelay = 5 * time.Second
+
+// Like exec.CommandContext but kills the entire process group once the context
+// is done (where supported, see setKillProcessGroup). This is necessary for
+// detectors that are started through wrapper scripts (e.g. dependency-check.sh
+// starting java) as their child processes would otherwise be left behind.
+func CommandContext(ctx context.Context, name string, arg ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, arg...)
+	setKillProcessGroup(cmd)
+	cmd.WaitDelay = commandWaitDelay
+
+	return cmd
+}
+
+// Flags whose values are secrets (e.g. the NVD API key of dependency-check) and are not logged.
+var secretFlags = []string{"--nvdApiKey"}
+
+const redactedFlagValue = "[REDACTED]"
+
+// Like cmd.Output() but logs the command line, its duration and its exit code in debug mode.
+func CommandOutput(cmd *exec.Cmd) ([]byte, error) {
+	logging.Debug("running command", "command", getLoggableCommandLine(cmd), "dir", cmd.Dir)
+
+	startTime := time.Now()
+	out, err := cmd.Output()
+
+	exitCode := -1 // the command could not be started or was killed
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+
+	stderr := ""
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		stderr = string(exitError.Stderr)
+	}
+
+	logging.Debug("command finished", "command", cmd.Path, "duration", time.Since(startTime),
+		"exitCode", exitCode, "stderr", stderr)
+
+	return out, err
+}
+
+// Like cmd.String() but with the values of secretFlags redacted.
+func getLoggableCommandLine(cmd *exec.Cmd) string {
+	args := slices.Clone(cmd.Args)
+	for i, arg := range args {
+		for _, secretFlag := range secretFlags {
+			if arg == secretFlag && i+1 < len(args) {
+				args[i+1] = redactedFlagValue
+			} else if strings.HasPrefix(arg, secretFlag+"=") {
+				args[i] = secretFlag + "=" + redactedFlagValue
+			}
+		}
+	}
+
+	if len(args) > 0 {
+		args[0] = cmd.Path
+	}
+
+	return strings.Join(args, " ")
+}
+
+// Maximum number of lines of stderr kept in CommandError.Stderr.
+const commandErrorMaxStderrLines = 20
+const commandErrorMaxSummaryLength = 300
+
+var ansiEscapeSequenceRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// A command that could not be run or exited with an exit code that signifies failure.
+type CommandError struct {
+	Name     string // of the program
+	ExitCode int    // -1 if the program could not be started or was killed
+	Stderr   string // last lines of stderr without color codes
+	Err      error
+}
+
+func (commandError *CommandError) Error() string {
+	if commandError.ExitCode == -1 {
+		return commandError.Name + " could not be run: " + commandError.Err.Error()
+	}
+
+	description := fmt.Sprintf("%s exited with exit code %d", commandError.Name, commandError.ExitCode)
+
+	// The last line usually tells what went wrong.
+	lines := strings.Split(commandError.Stderr, "\n")
+	summary := lines[len(lines)-1]
+	if summary == "" {
+		return description
+	}
+
+	if len(summary) > commandErrorMaxSummaryLength {
+		summary = summary[:commandErrorMaxSummaryLength] + "..."
+	}
+
+	return description + ": " + summary
+}
+
+func (commandError *CommandError) Unwrap() error {
+	return commandError.Err
+}
+
+/**
+ * Turns the error returned by CommandOutput into a *CommandError unless the command has
+ * exited with one of the given exit codes (e.g. those signifying that findings were found).
+ */
+func CheckExitCode(name string, err error, successExitCodes ...int) error {
+	if err == nil {
+		return nil
+	}
+
+	var exitError *exec.ExitError
+	if !errors.As(err, &exitError) {
+		return &CommandError{Name: name, ExitCode: -1, Stderr: "", Err: err}
+	}
+
+	if slices.Contains(successExitCodes, exitError.ExitCode()) {
+		return nil
+	}
+
+	return &CommandError{
+		Name:     name,
+		ExitCode: exitError.ExitCode(),
+		Stderr:   getStderrTail(exitError.Stderr),
+		Err:      err,
+	}
+}
+
+func getStderrTail(stderr []byte) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(ansiEscapeSequenceRegex.ReplaceAllString(string(stderr), ""), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) > commandErrorMaxStderrLines {
+		lines = lines[len(lines)-commandErrorMaxStderrLines:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+/**
+ * Reads a report that a program has written to a file. A missing or empty report means that
+ * the program has failed regardless of its exit code. The report path has to be in a directory
+ * created for the run so that a report of a previous run cannot be mistaken for the current one.
+ */
+func ReadReportFile(name string, reportPath string) ([]byte, error) {
+	report, err := os.ReadFile(reportPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New(name + " did not write a report")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(strings.TrimSpace(string(report))) == 0 {
+		return nil, errors.New(name + " wrote an empty report")
+	}
+
+	return report, nil
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package utils
+
+import (
+	"os/exec"
+)
+
+// Windows has no process groups that can be killed as a whole, so only the process itself is
+// killed on cancellation. Child processes that it has started may be left behind.
+func setKillProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+}